@@ -0,0 +1,297 @@
+package main
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	diplo "github.com/adambyle/diplopad"
+)
+
+// Kinds of message in the DAIDE client-server protocol.
+const (
+	initialMessage        = 0
+	representationMessage = 1
+	diplomacyMessage      = 2
+	finalMessage          = 3
+	errorMessage          = 4
+)
+
+// Error codes sent in error messages.
+const (
+	errTimer        = 0x01 // no initial message in time
+	errNotFirst     = 0x02 // the initial message was not first
+	errEndian       = 0x03
+	errMagic        = 0x04
+	errVersion      = 0x05
+	errRepeated     = 0x06 // a second initial message
+	errUnknown      = 0x08 // unknown kind of message
+	errShort        = 0x09 // message shorter than its length
+	errClientRM     = 0x0D // representation message from a client
+	errInvalidToken = 0x0E
+)
+
+const (
+	daideVersion = 1
+	daideMagic   = 0xDA10
+)
+
+// daideTokens is the tokens of the protocol that do not depend on the board.
+var daideTokens = map[string]uint16{
+	"(": 0x4000, ")": 0x4001,
+	"AMY": 0x4200, "FLT": 0x4201,
+	"CTO": 0x4320, "CVY": 0x4321, "HLD": 0x4322, "MTO": 0x4323, "SUP": 0x4324, "VIA": 0x4325,
+	"DSB": 0x4340, "RTO": 0x4341,
+	"BLD": 0x4380, "REM": 0x4381, "WVE": 0x4382,
+	"MBV": 0x4400, "BPR": 0x4401, "CST": 0x4402, "ESC": 0x4403, "FAR": 0x4404,
+	"HSC": 0x4405, "NAS": 0x4406, "NMB": 0x4407, "NMR": 0x4408, "NRN": 0x4409,
+	"NRS": 0x440A, "NSA": 0x440B, "NSC": 0x440C, "NSF": 0x440D, "NSP": 0x440E,
+	"NST": 0x440F, "NSU": 0x4410, "NVR": 0x4411, "NYU": 0x4412, "YSC": 0x4413,
+	"SUC": 0x4500, "BNC": 0x4501, "CUT": 0x4502, "DSR": 0x4503, "FLD": 0x4504,
+	"NSO": 0x4505, "RET": 0x4506,
+	"NCS": 0x4600, "NEC": 0x4602, "ECS": 0x4604, "SEC": 0x4606,
+	"SCS": 0x4608, "SWC": 0x460A, "WCS": 0x460C, "NWC": 0x460E,
+	"SPR": 0x4700, "SUM": 0x4701, "FAL": 0x4702, "AUT": 0x4703, "WIN": 0x4704,
+	"CCD": 0x4800, "DRW": 0x4801, "FRM": 0x4802, "GOF": 0x4803, "HLO": 0x4804,
+	"HST": 0x4805, "HUH": 0x4806, "IAM": 0x4807, "LOD": 0x4808, "MAP": 0x4809,
+	"MDF": 0x480A, "MIS": 0x480B, "NME": 0x480C, "NOT": 0x480D, "NOW": 0x480E,
+	"OBS": 0x480F, "OFF": 0x4810, "ORD": 0x4811, "OUT": 0x4812, "PRN": 0x4813,
+	"REJ": 0x4814, "SCO": 0x4815, "SLO": 0x4816, "SND": 0x4817, "SUB": 0x4818,
+	"SVE": 0x4819, "THX": 0x481A, "TME": 0x481B, "YES": 0x481C, "ADM": 0x481D,
+	"SMR": 0x481E,
+	"AOA": 0x4900, "BTL": 0x4901, "ERR": 0x4902, "LVL": 0x4903, "MRT": 0x4904,
+	"MTL": 0x4905, "NPB": 0x4906, "NPR": 0x4907, "PDA": 0x4908, "PTL": 0x4909,
+	"RTL": 0x490A, "UNO": 0x490B, "DSD": 0x490D,
+}
+
+const (
+	textCategory    = 0x4B
+	powerCategory   = 0x41
+	provinceInland  = 0x50 // plus one for supply centers
+	provinceSea     = 0x52
+	provinceCoastal = 0x54
+	provinceCoasts  = 0x56 // with several named coasts
+)
+
+// daideCodec translates between DAIDE messages as tokens on the wire and as
+// text, like "NME ( 'Bot' ) ( '1.0' )", for a board.
+type daideCodec struct {
+	codes  map[string]uint16
+	tokens map[uint16]string
+	// board is the tokens for the board's countries and provinces, in order.
+	board []uint16
+}
+
+// newDAIDECodec numbers the board's countries in order and its provinces by
+// their kind, in the order of their tokens, as the standard board is numbered.
+func newDAIDECodec(board *diplo.Board) (*daideCodec, error) {
+	c := &daideCodec{
+		codes:  make(map[string]uint16),
+		tokens: make(map[uint16]string),
+	}
+	for token, code := range daideTokens {
+		c.codes[token] = code
+		c.tokens[code] = token
+	}
+	add := func(token string, code uint16) error {
+		if _, ok := c.codes[token]; ok {
+			return fmt.Errorf("token %s is used twice", token)
+		}
+		c.codes[token] = code
+		c.tokens[code] = token
+		c.board = append(c.board, code)
+		return nil
+	}
+	for i, country := range board.Countries() {
+		if err := add(diplo.DAIDECountry(country), powerCategory<<8|uint16(i)); err != nil {
+			return nil, err
+		}
+	}
+	type province struct {
+		token    string
+		category uint16
+	}
+	var provinces []province
+	for p := range board.Provinces() {
+		category := uint16(provinceInland)
+		switch {
+		case p.Terrain() == diplo.Water:
+			category = provinceSea
+		case p.Terrain() == diplo.Coastal && len(p.Coasts()) > 0:
+			category = provinceCoasts
+		case p.Terrain() == diplo.Coastal:
+			category = provinceCoastal
+		}
+		if p.Center() {
+			category++
+		}
+		provinces = append(provinces, province{board.DAIDEProvince(p), category})
+	}
+	if len(provinces) > 256 {
+		return nil, errors.New("too many provinces for DAIDE")
+	}
+	slices.SortFunc(provinces, func(a, b province) int {
+		return cmp.Or(cmp.Compare(a.category, b.category), strings.Compare(a.token, b.token))
+	})
+	for i, p := range provinces {
+		if err := add(p.token, p.category<<8|uint16(i)); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// representation is the body of the representation message, naming each of
+// the board's tokens.
+func (c *daideCodec) representation() []byte {
+	var body []byte
+	for _, code := range c.board {
+		body = binary.BigEndian.AppendUint16(body, code)
+		body = append(body, c.tokens[code]...)
+		body = append(body, 0)
+	}
+	return body
+}
+
+// decode gives the text of a diplomacy message's tokens. Text is given in
+// single quotes and numbers in decimal.
+func (c *daideCodec) decode(body []byte) (string, error) {
+	if len(body)%2 != 0 {
+		return "", errors.New("odd message length")
+	}
+	var words []string
+	var text []byte
+	for i := 0; i < len(body); i += 2 {
+		code := binary.BigEndian.Uint16(body[i:])
+		if code>>8 == textCategory {
+			text = append(text, byte(code))
+			continue
+		}
+		if text != nil {
+			words = append(words, "'"+string(text)+"'")
+			text = nil
+		}
+		switch token, ok := c.tokens[code]; {
+		case ok:
+			words = append(words, token)
+		case code < 0x2000:
+			words = append(words, strconv.Itoa(int(code)))
+		case code < 0x4000:
+			words = append(words, strconv.Itoa(int(code)-0x4000))
+		default:
+			return "", fmt.Errorf("invalid token 0x%04X", code)
+		}
+	}
+	if text != nil {
+		words = append(words, "'"+string(text)+"'")
+	}
+	return strings.Join(words, " "), nil
+}
+
+// encode gives the tokens of a diplomacy message's text.
+func (c *daideCodec) encode(message string) ([]byte, error) {
+	var body []byte
+	for _, word := range splitDAIDE(message) {
+		if text, ok := strings.CutPrefix(word, "'"); ok {
+			for _, b := range []byte(strings.TrimSuffix(text, "'")) {
+				body = binary.BigEndian.AppendUint16(body, textCategory<<8|uint16(b))
+			}
+			continue
+		}
+		if code, ok := c.codes[strings.ToUpper(word)]; ok {
+			body = binary.BigEndian.AppendUint16(body, code)
+			continue
+		}
+		n, err := strconv.Atoi(word)
+		if err != nil || n < -0x2000 || n >= 0x2000 {
+			return nil, fmt.Errorf("unknown token %s", word)
+		}
+		body = binary.BigEndian.AppendUint16(body, uint16(n)&0x3FFF)
+	}
+	return body, nil
+}
+
+// splitDAIDE splits a message's text into words, each a parenthesis, quoted
+// text, or token.
+func splitDAIDE(message string) []string {
+	var words []string
+	for i := 0; i < len(message); {
+		switch ch := message[i]; {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			i++
+		case ch == '(' || ch == ')':
+			words = append(words, message[i:i+1])
+			i++
+		case ch == '\'':
+			end := strings.IndexByte(message[i+1:], '\'')
+			if end < 0 {
+				words = append(words, message[i:]+"'")
+				i = len(message)
+				break
+			}
+			words = append(words, message[i:i+end+2])
+			i += end + 2
+		default:
+			end := strings.IndexAny(message[i:], " \t\n()'")
+			if end < 0 {
+				end = len(message) - i
+			}
+			words = append(words, message[i:i+end])
+			i += end
+		}
+	}
+	return words
+}
+
+// groups splits a message's words after the first into the expressions in
+// parentheses at the top level, like the orders of a SUB message. Words
+// outside parentheses are left out.
+func groups(message string) []string {
+	var result, group []string
+	depth := 0
+	for _, word := range splitDAIDE(message)[1:] {
+		switch word {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		if depth > 0 || word == ")" {
+			group = append(group, word)
+		}
+		if depth == 0 && group != nil {
+			result = append(result, strings.Join(group, " "))
+			group = nil
+		}
+	}
+	return result
+}
+
+// readMessage reads a message's kind and body.
+func readMessage(r io.Reader) (byte, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	body := make([]byte, binary.BigEndian.Uint16(header[2:]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header[0], body, nil
+}
+
+// writeMessage writes a message with its header.
+func writeMessage(w io.Writer, kind byte, body []byte) error {
+	if len(body) > 0xFFFF {
+		return errors.New("message too long")
+	}
+	message := []byte{kind, 0, 0, 0}
+	binary.BigEndian.PutUint16(message[2:], uint16(len(body)))
+	_, err := w.Write(append(message, body...))
+	return err
+}
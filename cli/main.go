@@ -1,14 +1,63 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"os"
 	"slices"
+	"strings"
 
 	diplo "github.com/adambyle/diplopad"
 )
 
+// commands are run by name, as the first argument, with the arguments
+// after it.
+var commands = map[string]func(args []string) error{
+	"connections": printConnections,
+	"play":        playCommand,
+	"serve-daide": serveDAIDE,
+	"show":        showCommand,
+}
+
 func main() {
-	board := diplo.StandardBoard
+	if len(os.Args) < 2 {
+		usage()
+	}
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "diplocli: unknown command %s\n", os.Args[1])
+		usage()
+	}
+	if err := command(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "diplocli:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: diplocli <command> [arguments]")
+	names := slices.Sorted(maps.Keys(commands))
+	fmt.Fprintln(os.Stderr, "commands:", strings.Join(names, ", "))
+	os.Exit(2)
+}
+
+// printConnections lists the connections from each province of a variant's
+// board, the standard board if none is named.
+func printConnections(args []string) error {
+	name := "Standard"
+	switch len(args) {
+	case 0:
+	case 1:
+		name = args[0]
+	default:
+		return errors.New("usage: diplocli connections [variant]")
+	}
+	variant, ok := diplo.RegisteredVariant(name)
+	if !ok {
+		return fmt.Errorf("unknown variant %s", name)
+	}
+	board := variant.Board
 	for p := range board.Provinces() {
 		cs := slices.Collect(board.ConnectionsFrom(p))
 		fmt.Println(p.Name(), len(cs))
@@ -20,4 +69,5 @@ func main() {
 			}
 		}
 	}
+	return nil
 }
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	diplo "github.com/adambyle/diplopad"
+)
+
+const playHelp = `Type an order to add it, like "A Par - Bur" or "France: Build A Par".
+The country is found from the unit when not given. Other commands:
+  remove <order>          remove an order
+  clear <country>         remove a country's orders
+  orders                  list the orders given, with their outcomes
+  unordered               list the units without orders
+  fillin                  give the default order to each unit without one
+  civil-disorder [country]
+                          replace the orders with the defaults, for one
+                          country or all of them
+  go                      adjudicate the phase and move on to the next
+  back                    return to the phase before, to give it other
+                          orders
+  show                    summarize the position
+  save <file>             write the game so far as JSON
+  help                    show this help
+  quit                    leave`
+
+// playCommand runs an interactive session for entering orders and
+// adjudicating phases, starting from a saved position or a variant's
+// starting position. A saved history or record continues from the end of
+// its main line.
+func playCommand(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	variantName := fs.String("variant", "Standard", "variant to start, or whose board position notation is on")
+	colorMode := fs.String("color", "auto", "whether to color countries: auto, always, or never")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: diplocli play [flags] [file or notation]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	variant, ok := diplo.RegisteredVariant(*variantName)
+	if !ok {
+		return fmt.Errorf("unknown variant %s", *variantName)
+	}
+	game, err := variant.NewGame()
+	if err != nil {
+		return err
+	}
+	history := diplo.NewHistory(game)
+	if fs.NArg() == 1 {
+		if history, err = loadHistory(fs.Arg(0), variant); err != nil {
+			return err
+		}
+		if v, ok := diplo.VariantOf(history.Root().Game().Board()); ok {
+			variant = v
+		}
+	}
+	color, err := useColor(*colorMode, os.Stdout)
+	if err != nil {
+		return err
+	}
+	line := history.MainLine()
+	position := line[len(line)-1]
+	p := &player{
+		out:      os.Stdout,
+		variant:  variant,
+		color:    color,
+		history:  history,
+		position: position,
+		arena:    position.Game().Arena(),
+	}
+	p.show()
+	return p.run(os.Stdin)
+}
+
+// player is an interactive session of entering orders and playing phases.
+type player struct {
+	out      io.Writer
+	variant  diplo.Variant
+	color    bool
+	history  *diplo.History
+	position *diplo.Position
+	arena    *diplo.Arena
+}
+
+func (p *player) game() *diplo.Game {
+	return p.position.Game()
+}
+
+func (p *player) run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprintf(p.out, "%s> ", p.game().Label())
+		if !scanner.Scan() {
+			fmt.Fprintln(p.out)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if line == "quit" || line == "exit" {
+			return nil
+		}
+		if err := p.command(line); err != nil {
+			fmt.Fprintln(p.out, "error:", err)
+		}
+	}
+}
+
+func (p *player) command(line string) error {
+	command, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+	switch strings.ToLower(command) {
+	case "help", "?":
+		fmt.Fprintln(p.out, playHelp)
+	case "remove":
+		country, order, err := p.parseOrder(rest)
+		if err != nil {
+			return err
+		}
+		before := p.outcomes()
+		p.arena.Remove(country, *order)
+		p.changes(before)
+	case "clear":
+		country, err := p.parseCountry(rest)
+		if err != nil {
+			return err
+		}
+		before := p.outcomes()
+		p.arena.Clear(country)
+		p.changes(before)
+	case "orders":
+		p.orders()
+	case "unordered":
+		p.unordered()
+	case "fillin":
+		before := p.outcomes()
+		p.arena.FillIn()
+		p.changes(before)
+	case "civil-disorder":
+		return p.civilDisorder(rest)
+	case "go":
+		p.play()
+	case "back":
+		parent := p.position.Parent()
+		if parent == nil {
+			return errors.New("already at the first phase")
+		}
+		for idle(parent.Game()) && parent.Parent() != nil {
+			parent = parent.Parent()
+		}
+		p.position = parent
+		p.arena = parent.Game().Arena()
+		p.show()
+	case "show":
+		p.show()
+	case "save":
+		return p.save(rest)
+	default:
+		return p.add(line)
+	}
+	return nil
+}
+
+// parseCountry interprets a country's name on the board.
+func (p *player) parseCountry(name string) (string, error) {
+	board := p.game().Board()
+	country, ok := board.ParseCountry(name)
+	if !ok || !slices.Contains(board.Countries(), country) {
+		return "", &diplo.UnknownCountryError{Name: name}
+	}
+	return country, nil
+}
+
+// parseOrder interprets an order, with the country giving it before a colon,
+// or else found from the unit ordered, or the home center built on.
+func (p *player) parseOrder(text string) (string, *diplo.Order, error) {
+	g := p.game()
+	if name, order, ok := strings.Cut(text, ":"); ok {
+		country, err := p.parseCountry(strings.TrimSpace(name))
+		if err != nil {
+			return "", nil, err
+		}
+		o, err := g.ParseOrder(strings.TrimSpace(order), country)
+		return country, o, err
+	}
+	o, err := g.ParseOrder(text, "")
+	if err != nil {
+		return "", nil, err
+	}
+	var country string
+	switch {
+	case o.Kind() == diplo.Build:
+		country, _ = o.Target.Country()
+	case g.Phase().Retreat():
+		if u := g.DislodgedUnit(o.Unit); u != nil {
+			country = u.Country()
+		}
+	case o.Unit != nil:
+		if u := g.Unit(o.Unit); u != nil {
+			country = u.Country()
+		}
+	}
+	if country == "" {
+		return "", nil, fmt.Errorf("cannot tell which country orders %s; write it like \"France: %[1]s\"", text)
+	}
+	o, err = g.ParseOrder(text, country)
+	return country, o, err
+}
+
+// add adds an order and shows its outcome, and the outcomes it changes.
+func (p *player) add(text string) error {
+	country, order, err := p.parseOrder(text)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "would be %v\n", p.arena.Query(country, *order))
+	before := p.outcomes()
+	if _, err := p.arena.Add(country, *order); err != nil {
+		return err
+	}
+	p.changes(before)
+	return nil
+}
+
+// givenOrder is an order with the country giving it.
+type givenOrder struct {
+	country string
+	order   diplo.Order
+}
+
+func (p *player) outcomes() map[givenOrder]diplo.Outcome {
+	outcomes := make(map[givenOrder]diplo.Outcome)
+	for _, c := range p.game().Board().Countries() {
+		for o, outcome := range p.arena.Outcomes(c) {
+			outcomes[givenOrder{c, o}] = outcome
+		}
+	}
+	return outcomes
+}
+
+// changes prints the orders added, removed, or with a new outcome since
+// before.
+func (p *player) changes(before map[givenOrder]diplo.Outcome) {
+	after := p.outcomes()
+	var lines []string
+	for o, outcome := range after {
+		if old, ok := before[o]; !ok {
+			lines = append(lines, fmt.Sprintf("+ %s => %v", p.format(o), outcome))
+		} else if old != outcome {
+			lines = append(lines, fmt.Sprintf("  %s => %v (was %v)", p.format(o), outcome, old))
+		}
+	}
+	for o := range before {
+		if _, ok := after[o]; !ok {
+			lines = append(lines, fmt.Sprintf("- %s", p.format(o)))
+		}
+	}
+	slices.SortFunc(lines, func(a, b string) int {
+		return strings.Compare(a[2:], b[2:])
+	})
+	for _, line := range lines {
+		fmt.Fprintln(p.out, line)
+	}
+}
+
+func (p *player) format(o givenOrder) string {
+	country := paint(o.country, p.variant.Country(o.country).Color, p.color)
+	return country + ": " + o.order.Format(p.game(), diplo.StyleWebDip)
+}
+
+func (p *player) orders() {
+	var lines []string
+	for o, outcome := range p.outcomes() {
+		lines = append(lines, fmt.Sprintf("%s => %v", p.format(o), outcome))
+	}
+	slices.Sort(lines)
+	if len(lines) == 0 {
+		fmt.Fprintln(p.out, "no orders")
+	}
+	for _, line := range lines {
+		fmt.Fprintln(p.out, line)
+	}
+}
+
+func (p *player) unordered() {
+	var lines []string
+	for u := range p.arena.Unordered() {
+		lines = append(lines, paint(u.Country(), p.variant.Country(u.Country()).Color, p.color)+": "+unitName(u))
+	}
+	slices.Sort(lines)
+	if len(lines) == 0 {
+		fmt.Fprintln(p.out, "every unit has an order")
+	}
+	for _, line := range lines {
+		fmt.Fprintln(p.out, line)
+	}
+}
+
+// civilDisorder replaces the orders of a country, or of every country, with
+// the defaults given to countries in civil disorder.
+func (p *player) civilDisorder(name string) error {
+	before := p.outcomes()
+	defaults := p.game().CivilDisorder()
+	if name == "" {
+		p.arena = defaults
+		p.changes(before)
+		return nil
+	}
+	country, err := p.parseCountry(name)
+	if err != nil {
+		return err
+	}
+	p.arena.Clear(country)
+	for _, o := range defaults.Orders(country) {
+		if _, err := p.arena.Add(country, o); err != nil {
+			return err
+		}
+	}
+	p.changes(before)
+	return nil
+}
+
+// play adjudicates the phase and moves on, through any phases after it that
+// need no orders.
+func (p *player) play() {
+	for {
+		next, err := p.position.Play(p.arena)
+		if err != nil {
+			fmt.Fprintln(p.out, "error:", err)
+			return
+		}
+		for _, r := range next.Orders() {
+			fmt.Fprintf(p.out, "%s => %v\n", p.format(givenOrder{r.Country, r.Order}), r.Outcome)
+		}
+		p.position = next
+		p.arena = next.Game().Arena()
+		if !idle(next.Game()) {
+			break
+		}
+	}
+	fmt.Fprintln(p.out)
+	p.show()
+	if winner, ok := p.game().Winner(); ok {
+		fmt.Fprintf(p.out, "%s has won.\n", winner)
+	}
+}
+
+// idle tells whether a phase needs no orders: a retreat phase with no
+// dislodged units, or a Winter phase with no builds or disbands to make.
+func idle(g *diplo.Game) bool {
+	switch {
+	case g.Phase().Retreat():
+		for range g.AllDislodged() {
+			return false
+		}
+		return true
+	case g.Phase() == diplo.Winter:
+		for _, c := range g.Board().Countries() {
+			n := g.CenterCount(c) - g.UnitCount(c)
+			if n < 0 || n > 0 && g.OpenHomeCenterCount(c) > 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (p *player) show() {
+	printPosition(p.out, p.game(), p.variant, p.color)
+}
+
+// save writes every phase played, with the variations left by going back,
+// as JSON.
+func (p *player) save(path string) error {
+	if path == "" {
+		return errors.New("usage: save <file>")
+	}
+	data, err := json.MarshalIndent(p.history, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintln(p.out, "saved", path)
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	diplo "github.com/adambyle/diplopad"
+)
+
+// loadPosition reads a game as with [loadHistory], giving the last position
+// of the history's main line.
+func loadPosition(arg string, variant diplo.Variant) (*diplo.Game, error) {
+	h, err := loadHistory(arg, variant)
+	if err != nil {
+		return nil, err
+	}
+	line := h.MainLine()
+	return line[len(line)-1].Game(), nil
+}
+
+// loadHistory reads a game from a file, or from the argument itself if no
+// such file exists. It may be a game or history saved as JSON, a game record,
+// or position notation on the variant's board.
+func loadHistory(arg string, variant diplo.Variant) (*diplo.History, error) {
+	data, err := os.ReadFile(arg)
+	if errors.Is(err, os.ErrNotExist) {
+		data = []byte(arg)
+	} else if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(data, []byte("{")):
+		var probe map[string]json.RawMessage
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return nil, err
+		}
+		if _, ok := probe["Game"]; ok {
+			var h diplo.History
+			if err := json.Unmarshal(data, &h); err != nil {
+				return nil, fmt.Errorf("reading history: %w", err)
+			}
+			return &h, nil
+		}
+		var g diplo.Game
+		if err := json.Unmarshal(data, &g); err != nil {
+			return nil, fmt.Errorf("reading game: %w", err)
+		}
+		return diplo.NewHistory(&g), nil
+	case bytes.HasPrefix(data, []byte("[")):
+		return diplo.ReadRecord(bytes.NewReader(data), nil)
+	default:
+		g, err := diplo.ParseNotation(variant.Board, string(data))
+		if err != nil {
+			return nil, err
+		}
+		return diplo.NewHistory(g), nil
+	}
+}
@@ -0,0 +1,491 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/bits"
+	"math/rand/v2"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	diplo "github.com/adambyle/diplopad"
+)
+
+// serveDAIDE runs a DAIDE server for a game of a registered variant, the
+// standard game unless chosen with -variant, for bots to play over TCP.
+//
+// Clients join as players with NME, or watch with OBS. When every power has
+// a player who has accepted the map, powers are handed out at random with
+// HLO and the game starts. Each phase is adjudicated once every player has
+// submitted all their orders, or when the deadline passes, if one is set.
+func serveDAIDE(args []string) error {
+	fs := flag.NewFlagSet("serve-daide", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:16713", "address to listen on")
+	deadline := fs.Duration("deadline", 0, "time allowed for each phase's orders, or 0 to wait for every player")
+	endYear := fs.Int("end-year", 0, "last year to play before declaring a draw, or 0 to play until a power wins")
+	variantName := fs.String("variant", "Standard", "name of the variant to play")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %s", strings.Join(fs.Args(), " "))
+	}
+	variant, ok := diplo.RegisteredVariant(*variantName)
+	if !ok {
+		return fmt.Errorf("unknown variant %s", *variantName)
+	}
+	board := variant.Board
+	codec, err := newDAIDECodec(board)
+	if err != nil {
+		return err
+	}
+	game, err := variant.NewGame()
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	s := &daideServer{
+		board:    board,
+		name:     strings.ToUpper(strings.ReplaceAll(variant.Name, " ", "")),
+		codec:    codec,
+		deadline: *deadline,
+		endYear:  *endYear,
+		events:   make(chan daideEvent),
+		done:     make(chan struct{}),
+		game:     game,
+		log:      log.New(os.Stderr, "serve-daide: ", log.LstdFlags),
+	}
+	s.log.Printf("listening on %s", ln.Addr())
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				s.post(daideEvent{err: err})
+				return
+			}
+			go s.receive(conn)
+		}
+	}()
+	return s.run()
+}
+
+type daideClient struct {
+	conn     net.Conn
+	name     string
+	power    string // empty for observers
+	passcode int
+	player   bool // joined with NME
+	observer bool // joined with OBS
+	ready    bool // accepted the map
+	gone     bool
+}
+
+// daideEvent is a message from a client, or the end of its connection, or
+// a phase's deadline passing.
+type daideEvent struct {
+	client   *daideClient
+	message  string
+	code     int // error code to send the client before closing
+	err      error
+	deadline int // phase whose deadline passed
+}
+
+type daideServer struct {
+	board    *diplo.Board
+	name     string // of the map
+	codec    *daideCodec
+	deadline time.Duration
+	endYear  int
+	events   chan daideEvent
+	done     chan struct{} // closed when the game is over, to stop senders of events
+	clients  []*daideClient
+	game     *diplo.Game
+	arena    *diplo.Arena
+	phase    int // counts the phases played, for deadlines
+	over     bool
+	log      *log.Logger
+}
+
+// receive does the handshake with a client, then passes its messages on as
+// events until the connection ends.
+func (s *daideServer) receive(conn net.Conn) {
+	c := &daideClient{conn: conn}
+	fail := func(code int) {
+		writeMessage(conn, errorMessage, binary.BigEndian.AppendUint16(nil, uint16(code)))
+		conn.Close()
+	}
+	conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	kind, body, err := readMessage(conn)
+	switch {
+	case err != nil:
+		fail(errTimer)
+		return
+	case kind != initialMessage:
+		fail(errNotFirst)
+		return
+	case len(body) != 4:
+		fail(errShort)
+		return
+	case binary.BigEndian.Uint16(body[2:]) == bits.ReverseBytes16(daideMagic):
+		fail(errEndian)
+		return
+	case binary.BigEndian.Uint16(body[2:]) != daideMagic:
+		fail(errMagic)
+		return
+	case binary.BigEndian.Uint16(body) != daideVersion:
+		fail(errVersion)
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	if err := writeMessage(conn, representationMessage, s.codec.representation()); err != nil {
+		conn.Close()
+		return
+	}
+	if !s.post(daideEvent{client: c}) {
+		conn.Close()
+		return
+	}
+	for {
+		kind, body, err := readMessage(conn)
+		if err != nil {
+			s.post(daideEvent{client: c, err: err})
+			return
+		}
+		switch kind {
+		case diplomacyMessage:
+			message, err := s.codec.decode(body)
+			if err != nil {
+				s.post(daideEvent{client: c, code: errInvalidToken, err: err})
+				return
+			}
+			s.post(daideEvent{client: c, message: message})
+		case finalMessage, errorMessage:
+			s.post(daideEvent{client: c, err: io.EOF})
+			return
+		case initialMessage:
+			s.post(daideEvent{client: c, code: errRepeated, err: errors.New("repeated initial message")})
+			return
+		case representationMessage:
+			s.post(daideEvent{client: c, code: errClientRM, err: errors.New("representation message from client")})
+			return
+		default:
+			s.post(daideEvent{client: c, code: errUnknown, err: fmt.Errorf("unknown message kind %d", kind)})
+			return
+		}
+	}
+}
+
+// post passes an event on to run, unless the game is over. It tells whether
+// the event was passed on.
+func (s *daideServer) post(e daideEvent) bool {
+	select {
+	case s.events <- e:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// run handles events until the game is over.
+func (s *daideServer) run() error {
+	defer close(s.done)
+	for !s.over {
+		e := <-s.events
+		switch {
+		case e.client == nil && e.err != nil:
+			return e.err
+		case e.client == nil:
+			if e.deadline == s.phase && s.arena != nil {
+				s.adjudicate()
+			}
+		case e.err != nil:
+			if e.code != 0 {
+				writeMessage(e.client.conn, errorMessage, binary.BigEndian.AppendUint16(nil, uint16(e.code)))
+			}
+			s.drop(e.client, e.err)
+		case e.message == "":
+			s.clients = append(s.clients, e.client)
+			s.log.Printf("%s connected", e.client.conn.RemoteAddr())
+		default:
+			s.handle(e.client, e.message)
+		}
+		if s.arena == nil {
+			continue
+		}
+		if !slices.ContainsFunc(s.clients, func(c *daideClient) bool { return c.power != "" }) {
+			s.log.Printf("every player has left")
+			s.over = true
+		}
+		for !s.over && s.submitted() {
+			s.adjudicate()
+		}
+	}
+	for _, c := range s.clients {
+		s.send(c, "OFF")
+		writeMessage(c.conn, finalMessage, nil)
+		c.conn.Close()
+	}
+	return nil
+}
+
+// drop closes a client's connection. A player's units are given default
+// orders from then on; the game ends if no players are left.
+func (s *daideServer) drop(c *daideClient, err error) {
+	if c.gone {
+		return
+	}
+	c.gone = true
+	c.conn.Close()
+	for i, other := range s.clients {
+		if other == c {
+			s.clients = append(s.clients[:i], s.clients[i+1:]...)
+			break
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		s.log.Printf("%s disconnected", c.conn.RemoteAddr())
+	} else {
+		s.log.Printf("%s disconnected: %v", c.conn.RemoteAddr(), err)
+	}
+}
+
+// send writes a diplomacy message to a client.
+func (s *daideServer) send(c *daideClient, message string) {
+	if c.gone {
+		return
+	}
+	body, err := s.codec.encode(message)
+	if err != nil {
+		s.log.Printf("cannot send %s: %v", message, err)
+		return
+	}
+	if err := writeMessage(c.conn, diplomacyMessage, body); err != nil {
+		s.drop(c, err)
+	}
+}
+
+// broadcast sends a message to every client that has joined.
+func (s *daideServer) broadcast(message string) {
+	for _, c := range slices.Clone(s.clients) {
+		if c.player || c.observer {
+			s.send(c, message)
+		}
+	}
+}
+
+// handle replies to a client's message.
+func (s *daideServer) handle(c *daideClient, message string) {
+	command, rest, _ := strings.Cut(message, " ")
+	reject := func() {
+		s.send(c, "REJ ( "+message+" )")
+	}
+	switch command {
+	case "NME":
+		args := groups(message)
+		if c.player || s.arena != nil || s.players() == len(s.board.Countries()) || len(args) != 2 {
+			reject()
+			return
+		}
+		c.player, c.name = true, strings.Trim(args[0], "( )'")
+		s.send(c, "YES ( "+message+" )")
+		s.send(c, "MAP ( '"+s.name+"' )")
+	case "OBS":
+		if c.player {
+			reject()
+			return
+		}
+		c.observer = true
+		s.send(c, "YES ( "+message+" )")
+		s.send(c, "MAP ( '"+s.name+"' )")
+	case "MAP":
+		s.send(c, "MAP ( '"+s.name+"' )")
+	case "MDF":
+		s.send(c, s.board.DAIDEMap())
+	case "YES":
+		if strings.HasPrefix(rest, "( MAP") && (c.player || c.observer) && !c.ready {
+			c.ready = true
+			if s.arena != nil {
+				now, sco := s.game.DAIDEPosition()
+				s.send(c, sco)
+				s.send(c, now)
+			} else {
+				s.start()
+			}
+		}
+	case "REJ":
+		if strings.HasPrefix(rest, "( MAP") {
+			s.drop(c, errors.New("map rejected"))
+		}
+	case "HLO":
+		if c.power == "" {
+			reject()
+			return
+		}
+		s.send(c, s.hello(c))
+	case "NOW", "SCO":
+		if s.arena == nil {
+			reject()
+			return
+		}
+		now, sco := s.game.DAIDEPosition()
+		if command == "NOW" {
+			s.send(c, now)
+		} else {
+			s.send(c, sco)
+		}
+	case "SUB":
+		if c.power == "" || s.arena == nil {
+			reject()
+			return
+		}
+		for _, order := range groups(message) {
+			reply, err := s.arena.DAIDESubmit(c.power, order)
+			if err != nil {
+				s.send(c, "HUH ( ERR "+message+" )")
+				return
+			}
+			s.send(c, reply)
+		}
+		s.send(c, s.arena.DAIDEMissing(c.power))
+	case "MIS":
+		if c.power == "" || s.arena == nil {
+			reject()
+			return
+		}
+		s.send(c, s.arena.DAIDEMissing(c.power))
+	case "GOF", "NOT":
+		s.send(c, "YES ( "+message+" )")
+	case "OFF":
+		s.drop(c, io.EOF)
+	case "HUH", "PRN":
+		s.log.Printf("%s did not understand: %s", c.conn.RemoteAddr(), message)
+	default:
+		if _, ok := daideTokens[command]; ok {
+			reject()
+		} else {
+			s.send(c, "HUH ( ERR "+message+" )")
+		}
+	}
+}
+
+// players counts the clients that joined with NME.
+func (s *daideServer) players() int {
+	n := 0
+	for _, c := range s.clients {
+		if c.player {
+			n++
+		}
+	}
+	return n
+}
+
+// start hands out the powers and starts the game once every power has a
+// player who has accepted the map.
+func (s *daideServer) start() {
+	var players []*daideClient
+	for _, c := range s.clients {
+		if c.player && c.ready {
+			players = append(players, c)
+		}
+	}
+	countries := s.board.Countries()
+	if len(players) < len(countries) {
+		return
+	}
+	for i, j := range rand.Perm(len(countries)) {
+		players[i].power = countries[j]
+		players[i].passcode = rand.IntN(0x2000)
+		s.log.Printf("%s plays %s", players[i].name, countries[j])
+	}
+	for _, c := range players {
+		s.send(c, s.hello(c))
+	}
+	s.begin()
+}
+
+// hello is the HLO message telling a player its power, passcode, and the
+// variant being played.
+func (s *daideServer) hello(c *daideClient) string {
+	variant := "( LVL 0 )"
+	if seconds := int(s.deadline.Seconds()); seconds > 0 {
+		variant += fmt.Sprintf(" ( MTL %d ) ( RTL %d ) ( BTL %d )", seconds, seconds, seconds)
+	}
+	return fmt.Sprintf("HLO ( %s ) ( %d ) ( %s )", diplo.DAIDECountry(c.power), c.passcode, variant)
+}
+
+// begin sends the position of a new phase and starts taking orders, skipping
+// phases in which no orders are needed. The game ends instead if a power has
+// won or the last year has been played.
+func (s *daideServer) begin() {
+	for {
+		s.arena = s.game.Arena()
+		if s.needsOrders() {
+			break
+		}
+		s.game = s.arena.Go()
+	}
+	s.phase++
+	now, sco := s.game.DAIDEPosition()
+	s.broadcast(sco)
+	s.broadcast(now)
+	if winner, ok := s.game.Winner(); ok {
+		s.broadcast("SLO ( " + diplo.DAIDECountry(winner) + " )")
+		s.log.Printf("%s wins", winner)
+		s.over = true
+		return
+	}
+	if s.endYear > 0 && s.game.Year() > s.endYear {
+		s.broadcast("DRW")
+		s.log.Printf("draw after %d", s.endYear)
+		s.over = true
+		return
+	}
+	s.log.Printf("%s", s.game.Label())
+	if s.deadline > 0 {
+		phase := s.phase
+		time.AfterFunc(s.deadline, func() {
+			s.post(daideEvent{deadline: phase})
+		})
+	}
+}
+
+// needsOrders tells whether any power has orders to give in the phase.
+func (s *daideServer) needsOrders() bool {
+	for _, country := range s.board.Countries() {
+		if s.arena.DAIDEMissing(country) != "MIS" {
+			return true
+		}
+	}
+	return false
+}
+
+// submitted tells whether every player still connected has given all their
+// orders.
+func (s *daideServer) submitted() bool {
+	for _, c := range s.clients {
+		if c.power != "" && s.arena.DAIDEMissing(c.power) != "MIS" {
+			return false
+		}
+	}
+	return true
+}
+
+// adjudicate resolves the phase's orders, sends their results, and starts
+// the next phase.
+func (s *daideServer) adjudicate() {
+	next := s.arena.Go()
+	for _, result := range s.arena.DAIDEResults() {
+		s.broadcast(result)
+	}
+	s.game = next
+	s.begin()
+}
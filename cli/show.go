@@ -0,0 +1,208 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	diplo "github.com/adambyle/diplopad"
+)
+
+// showCommand prints a summary of a saved position: each country's units,
+// supply centers, and adjustments owed in Winter, and the dislodged units
+// with where they may retreat in retreat phases.
+func showCommand(args []string) error {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	variantName := fs.String("variant", "Standard", "variant whose board position notation is on")
+	colorMode := fs.String("color", "auto", "whether to color countries: auto, always, or never")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: diplocli show [flags] <file or notation>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	variant, ok := diplo.RegisteredVariant(*variantName)
+	if !ok {
+		return fmt.Errorf("unknown variant %s", *variantName)
+	}
+	game, err := loadPosition(fs.Arg(0), variant)
+	if err != nil {
+		return err
+	}
+	color, err := useColor(*colorMode, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if v, ok := diplo.VariantOf(game.Board()); ok {
+		variant = v
+	}
+	return printPosition(os.Stdout, game, variant, color)
+}
+
+// useColor decides whether to write ANSI colors: always, never, or, for
+// auto, when writing to a terminal and NO_COLOR is not set.
+func useColor(mode string, f *os.File) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		info, err := f.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0, nil
+	default:
+		return false, fmt.Errorf("unknown color mode %s", mode)
+	}
+}
+
+// paint writes text in a color like "#C62828" as an ANSI escape sequence.
+func paint(text, color string, on bool) string {
+	hex, ok := strings.CutPrefix(color, "#")
+	if !on || !ok || len(hex) != 6 {
+		return text
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return text
+	}
+	return fmt.Sprintf("\x1b[1;38;2;%d;%d;%dm%s\x1b[0m", rgb>>16, rgb>>8&0xFF, rgb&0xFF, text)
+}
+
+// unitName is a unit's type initial and province abbreviation, with its
+// coast, like "F StP(SC)".
+func unitName(u *diplo.Occupancy) string {
+	name := u.Unit().String()[:1] + " " + u.Province().Abbreviations()[0]
+	if coast, ok := u.Coast(); ok && coast != "" {
+		name += "(" + coast + ")"
+	}
+	return name
+}
+
+func abbreviation(p *diplo.Province) string {
+	return p.Abbreviations()[0]
+}
+
+func printPosition(w io.Writer, g *diplo.Game, v diplo.Variant, color bool) error {
+	var sb strings.Builder
+	season := strings.TrimSuffix(g.Phase().String(), "Retreats")
+	fmt.Fprintf(&sb, "%s %d %s (%s)\n", season, g.Year(), phaseKind(g.Phase()), g.Label())
+	countries := g.Board().Countries()
+	width := 0
+	for _, c := range countries {
+		width = max(width, len(c))
+	}
+	for _, c := range countries {
+		centers := g.CenterCount(c)
+		units := g.UnitCount(c)
+		if centers == 0 && units == 0 {
+			continue
+		}
+		sb.WriteByte('\n')
+		name := paint(fmt.Sprintf("%-*s", width, c), v.Country(c).Color, color)
+		line := fmt.Sprintf("%s  %-10s  %-8s", name, plural(centers, "center"), plural(units, "unit"))
+		if g.Phase() == diplo.Winter {
+			switch n := centers - units; {
+			case n > 0:
+				builds := min(n, g.OpenHomeCenterCount(c))
+				line += "  " + plural(builds, "build")
+				if builds < n {
+					line += fmt.Sprintf(" of %d owed (too few open home centers)", n)
+				}
+			case n < 0:
+				line += "  " + plural(-n, "disband")
+			}
+		}
+		sb.WriteString(strings.TrimRight(line, " ") + "\n")
+		var names []string
+		for u := range g.Units(c) {
+			names = append(names, unitName(u))
+		}
+		slices.Sort(names)
+		if len(names) > 0 {
+			fmt.Fprintf(&sb, "  units:   %s\n", strings.Join(names, ", "))
+		}
+		names = nil
+		for p := range g.Centers(c) {
+			names = append(names, abbreviation(p))
+		}
+		slices.Sort(names)
+		if len(names) > 0 {
+			fmt.Fprintf(&sb, "  centers: %s\n", strings.Join(names, ", "))
+		}
+	}
+	var neutral []string
+	for p := range g.Board().Centers() {
+		if _, ok := g.Center(p); !ok {
+			neutral = append(neutral, abbreviation(p))
+		}
+	}
+	if len(neutral) > 0 {
+		slices.Sort(neutral)
+		fmt.Fprintf(&sb, "\nUncontrolled centers: %s\n", strings.Join(neutral, ", "))
+	}
+	if g.Phase().Retreat() {
+		printRetreats(&sb, g, v, color)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// printRetreats lists each dislodged unit with the provinces it may retreat to.
+func printRetreats(sb *strings.Builder, g *diplo.Game, v diplo.Variant, color bool) {
+	dislodged := slices.SortedFunc(g.AllDislodged(), func(a, b *diplo.Occupancy) int {
+		return strings.Compare(abbreviation(a.Province()), abbreviation(b.Province()))
+	})
+	if len(dislodged) == 0 {
+		return
+	}
+	sb.WriteString("\nDislodged:\n")
+	for _, u := range dislodged {
+		var options []string
+		for _, order := range g.LegalOrders(u) {
+			if order.Kind() != diplo.MoveRetreat {
+				continue
+			}
+			if order.TargetCoast != "" {
+				options = append(options, abbreviation(order.Target)+"("+order.TargetCoast+")")
+			} else {
+				options = append(options, abbreviation(order.Target))
+			}
+		}
+		slices.Sort(options)
+		retreat := "must disband"
+		if len(options) > 0 {
+			retreat = "may retreat to " + strings.Join(options, ", ") + " or disband"
+		}
+		fmt.Fprintf(sb, "  %s %s %s\n", paint(u.Country(), v.Country(u.Country()).Color, color), unitName(u), retreat)
+	}
+}
+
+// plural writes a count of things, like "1 unit" or "2 units".
+func plural(n int, thing string) string {
+	if n == 1 {
+		return "1 " + thing
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
+
+// phaseKind is the kind of a phase, after its season.
+func phaseKind(p diplo.Phase) string {
+	switch {
+	case p.Move():
+		return "Movement"
+	case p.Retreat():
+		return "Retreats"
+	default:
+		return "Adjustments"
+	}
+}
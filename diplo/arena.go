@@ -2,9 +2,11 @@ package diplo
 
 import (
 	"errors"
+	"fmt"
 	"iter"
 	"maps"
 	"slices"
+	"strings"
 )
 
 type Outcome int
@@ -61,18 +63,71 @@ const (
 	// OutcomeUnowned says a unit cannot be built on a supply center not controlled
 	// by the building country.
 	OutcomeNotControlled
-	// OutcomeOccupied says a unit cannot be builty where a unit already is.
+	// OutcomeOccupied says a unit cannot be built, or retreat, where a unit already is.
 	OutcomeOccupied
+	// MOVES ONLY:
+	// OutcomeBadConvoy says a convoy was ordered by an Army, or by a Fleet
+	// that is not at sea.
+	OutcomeBadConvoy
 )
 
+var outcomeNames = [...]string{
+	OutcomeSuccess:              "Success",
+	OutcomeMalformed:            "Malformed",
+	OutcomeRepeatUnit:           "RepeatUnit",
+	OutcomeEnemyUnit:            "EnemyUnit",
+	OutcomeMissingUnit:          "MissingUnit",
+	OutcomeBadTerrain:           "BadTerrain",
+	OutcomeBadTarget:            "BadTarget",
+	OutcomeBadCoast:             "BadCoast",
+	OutcomeCoastAmbiguous:       "CoastAmbiguous",
+	OutcomeNoConvoy:             "NoConvoy",
+	OutcomeBadRecipient:         "BadRecipient",
+	OutcomeMissingRecipient:     "MissingRecipient",
+	OutcomeDislodged:            "Dislodged",
+	OutcomeCut:                  "Cut",
+	OutcomeWeak:                 "Weak",
+	OutcomeStandoff:             "Standoff",
+	OutcomeOverpowered:          "Overpowered",
+	OutcomeContested:            "Contested",
+	OutcomeBadRetreatToAttacker: "BadRetreatToAttacker",
+	OutcomeNoBuilds:             "NoBuilds",
+	OutcomeNoDisbands:           "NoDisbands",
+	OutcomeNotHome:              "NotHome",
+	OutcomeNotControlled:        "NotControlled",
+	OutcomeOccupied:             "Occupied",
+	OutcomeBadConvoy:            "BadConvoy",
+}
+
+// String is the name of the outcome without its "Outcome" prefix, e.g. "Standoff".
+func (o Outcome) String() string {
+	if o < 0 || int(o) >= len(outcomeNames) {
+		return fmt.Sprintf("Outcome(%d)", int(o))
+	}
+	return outcomeNames[o]
+}
+
+// ParseOutcome interprets the name of an outcome, as given by [Outcome.String].
+// Case is ignored.
+func ParseOutcome(name string) (Outcome, bool) {
+	for o, n := range outcomeNames {
+		if strings.EqualFold(n, name) {
+			return Outcome(o), true
+		}
+	}
+	return 0, false
+}
+
 type build struct {
-	unit  Unit
-	coast string
+	unit    Unit
+	coast   string
+	country string
 }
 
 type unitOrder struct {
 	order   Order
 	outcome Outcome
+	illegal bool // the unit holds instead
 }
 
 // Arena is an interactive helper for resolving orders.
@@ -82,17 +137,27 @@ type Arena struct {
 	game          *Game
 	countryOrders map[string]map[Order]Outcome
 	unitOrders    map[*Occupancy]*unitOrder
+	given         []givenOrder // in the order they were added
+	rules         RuleSet
 	// Move phase.
 	moving     map[*Occupancy]*Province    // where the unit is moving (successfully, after convoy resolution)
 	convoying  map[*Occupancy]bool         // Fleets that are convoying
 	supporters map[*Occupancy][]*Occupancy // the strength of a unit's order
-	attackers  map[*Occupancy]*Province    // provinces a successful attack on the unit came from
+	attackers  map[*Occupancy]*Province    // provinces a successful attack on the unit came from (nil if convoyed)
 	convoyed   map[*Occupancy]bool         // whether the unit successfully took a convoy route
+	contested  map[*Province]bool          // provinces left vacant by a standoff
 	// Retreat phase
 	retreaters map[*Province][]*Occupancy // units wanting to retreat to the province
 	// Build phase.
 	builds     map[*Province]build // successful
 	buildCount map[string]int
+	waived     map[string]bool // countries leaving builds unused
+}
+
+// givenOrder is an order added to an arena by a country.
+type givenOrder struct {
+	country string
+	order   Order
 }
 
 // Arena creates a new interactive set of unit orders.
@@ -101,6 +166,10 @@ func (g *Game) Arena() *Arena {
 		game:          g,
 		countryOrders: make(map[string]map[Order]Outcome),
 		unitOrders:    make(map[*Occupancy]*unitOrder),
+		rules:         g.rules,
+	}
+	for _, country := range g.board.countries {
+		a.countryOrders[country] = make(map[Order]Outcome)
 	}
 	switch {
 	case g.phase.Move():
@@ -109,11 +178,13 @@ func (g *Game) Arena() *Arena {
 		a.supporters = make(map[*Occupancy][]*Occupancy)
 		a.attackers = make(map[*Occupancy]*Province)
 		a.convoyed = make(map[*Occupancy]bool)
+		a.contested = make(map[*Province]bool)
 	case g.phase.Retreat():
 		a.retreaters = make(map[*Province][]*Occupancy)
 	case g.phase == Winter:
 		a.builds = make(map[*Province]build)
 		a.buildCount = make(map[string]int)
+		a.waived = make(map[string]bool)
 		for _, country := range g.board.countries {
 			a.buildCount[country] = g.CenterCount(country) - g.UnitCount(country)
 		}
@@ -149,7 +220,7 @@ func (g *Game) CivilDisorder() *Arena {
 }
 
 func (a *Arena) doMovePhase(country string, order Order) (*Occupancy, Outcome) {
-	if k := order.Kind(); k == InvalidOrder || k == Build {
+	if k := order.Kind(); k == InvalidOrder || k == Build || k == Waive {
 		return nil, OutcomeMalformed
 	}
 	unit := a.game.Unit(order.Unit)
@@ -162,8 +233,142 @@ func (a *Arena) doMovePhase(country string, order Order) (*Occupancy, Outcome) {
 	if _, ok := a.unitOrders[unit]; ok {
 		return nil, OutcomeRepeatUnit
 	}
-	// TODO
-	return unit, OutcomeSuccess
+	return unit, a.legal(unit, order)
+}
+
+// legal checks whether an order could be carried out by a unit in a move
+// phase, regardless of other orders.
+func (a *Arena) legal(unit *Occupancy, order Order) Outcome {
+	g := a.game
+	switch order.Kind() {
+	case MoveRetreat:
+		if order.Target == unit.province {
+			return OutcomeBadTarget
+		}
+		if !order.Target.terrain.Supports(unit.unit) {
+			return OutcomeBadTerrain
+		}
+		if unit.unit == Army {
+			if order.ViaConvoy && (unit.province.terrain != Coastal || order.Target.terrain != Coastal) {
+				return OutcomeBadTarget
+			}
+			if !g.HasDestination(unit, order.Target) {
+				return OutcomeBadTarget
+			}
+			return OutcomeSuccess
+		}
+		if !g.HasNeighbor(unit, order.Target) {
+			return OutcomeBadTarget
+		}
+		cs := g.board.Connection(unit.province, order.Target).toCoasts
+		tc := order.TargetCoast
+		if len(cs) > 1 && tc == "" && !a.rules.NoCoastNeeded {
+			return OutcomeCoastAmbiguous
+		}
+		if len(cs) > 0 && tc != "" && !hasStringFold(cs, tc) {
+			return OutcomeBadCoast
+		}
+	case SupportHold, SupportMove:
+		recipient := g.Unit(order.Recipient)
+		if recipient == nil {
+			return OutcomeMissingRecipient
+		}
+		if recipient == unit {
+			return OutcomeBadRecipient
+		}
+		// The supporter must be able to move where it supports.
+		to := order.Target
+		if to == nil {
+			to = order.Recipient
+		}
+		if to == unit.province || !g.HasNeighbor(unit, to) {
+			return OutcomeBadRecipient
+		}
+	case Convoy:
+		if unit.unit != Fleet || !g.convoyable(unit.province) {
+			return OutcomeBadConvoy
+		}
+		recipient := g.Unit(order.Recipient)
+		if recipient == nil {
+			return OutcomeMissingRecipient
+		}
+		if recipient.unit != Army {
+			return OutcomeBadRecipient
+		}
+		if order.Target.terrain != Coastal || recipient.province.terrain != Coastal {
+			return OutcomeBadTarget
+		}
+	}
+	return OutcomeSuccess
+}
+
+// orders gets the order each unit carries out in a move phase.
+// Units with illegal orders hold.
+func (a *Arena) orders() map[*Occupancy]Order {
+	orders := make(map[*Occupancy]Order, len(a.unitOrders))
+	for u, uo := range a.unitOrders {
+		if uo.illegal {
+			orders[u] = OrderHoldDisband(u.province)
+		} else {
+			orders[u] = uo.order
+		}
+	}
+	return orders
+}
+
+// adjudicate resolves every order given so far in a move phase,
+// updating their outcomes.
+func (a *Arena) adjudicate() {
+	r := newResolver(a.game, a.orders(), a.rules)
+	for _, u := range r.units {
+		if uo, ok := a.unitOrders[u]; ok && !uo.illegal {
+			a.setOutcome(u, r.outcome(u))
+		}
+	}
+	clear(a.moving)
+	clear(a.convoying)
+	clear(a.supporters)
+	clear(a.attackers)
+	clear(a.convoyed)
+	clear(a.contested)
+	for _, u := range r.units {
+		o := r.orders[u]
+		switch o.Kind() {
+		case MoveRetreat:
+			if r.resolve(u) {
+				a.moving[u] = o.Target
+				a.convoyed[u] = r.convoyed[u]
+			} else if r.outcome(u) == OutcomeStandoff {
+				a.contested[o.Target] = true
+			}
+		case Convoy:
+			if !r.void[u] {
+				a.convoying[u] = true
+			}
+		}
+		for _, s := range r.support[u] {
+			if r.resolve(s) {
+				a.supporters[u] = append(a.supporters[u], s)
+			}
+		}
+		if d := r.dislodger(u); d != nil {
+			if r.convoyed[d] {
+				// Dislodged units may retreat to where a convoyed attack came from.
+				a.attackers[u] = nil
+			} else {
+				a.attackers[u] = d.province
+			}
+		}
+	}
+	// A province is only left vacant by a standoff if nothing entered it.
+	for p := range a.contested {
+		for _, m := range r.into[p] {
+			if r.resolve(m) {
+				delete(a.contested, p)
+				break
+			}
+		}
+	}
 }
 
 func (a *Arena) doRetreatPhase(country string, order Order) (*Occupancy, Outcome) {
@@ -195,13 +400,16 @@ func (a *Arena) doRetreatPhase(country string, order Order) (*Occupancy, Outcome
 	if a.game.contests[order.Target] {
 		return unit, OutcomeContested
 	}
+	if a.game.Unit(order.Target) != nil {
+		return unit, OutcomeOccupied
+	}
 	if order.Target == a.game.attackers[unit] {
 		return unit, OutcomeBadRetreatToAttacker
 	}
 	if unit.unit == Fleet {
 		cs := a.game.board.Connection(unit.province, order.Target).toCoasts
 		tc := order.TargetCoast
-		if len(cs) > 1 && tc == "" {
+		if len(cs) > 1 && tc == "" && !a.rules.NoCoastNeeded {
 			return unit, OutcomeCoastAmbiguous
 		}
 		if len(cs) > 0 && tc != "" && !slices.Contains(cs, tc) {
@@ -210,14 +418,9 @@ func (a *Arena) doRetreatPhase(country string, order Order) (*Occupancy, Outcome
 	}
 	// If there are multiple retreaters to the target province,
 	// all of them fail (standoff) and will disband.
-	rs := append(a.retreaters[order.Target], unit)
-	a.retreaters[order.Target] = rs
-	if len(rs) == 1 {
+	if len(a.retreaters[order.Target]) == 0 {
 		return unit, OutcomeSuccess
 	} else {
-		for _, r := range rs {
-			a.unitOrders[r].outcome = OutcomeStandoff
-		}
 		return unit, OutcomeStandoff
 	}
 }
@@ -246,7 +449,7 @@ func (a *Arena) doBuildPhase(country string, order Order) (*Occupancy, Outcome)
 		if _, ok := a.builds[order.Target]; ok {
 			return nil, OutcomeRepeatUnit
 		}
-		if order.Target.country != country {
+		if order.Target.country != country && !a.rules.BuildAnywhere {
 			return nil, OutcomeNotHome
 		}
 		if c, _ := a.game.Center(order.Target); c != country {
@@ -258,8 +461,18 @@ func (a *Arena) doBuildPhase(country string, order Order) (*Occupancy, Outcome)
 		if !order.Target.terrain.Supports(order.Build) {
 			return nil, OutcomeBadTerrain
 		}
-		if c := order.Target.coasts; len(c) > 0 && !slices.Contains(c, order.TargetCoast) {
-			return nil, OutcomeCoastAmbiguous
+		if c := order.Target.coasts; order.Build == Fleet && len(c) > 0 {
+			if order.TargetCoast == "" && !a.rules.NoCoastNeeded {
+				return nil, OutcomeCoastAmbiguous
+			}
+			if order.TargetCoast != "" && !slices.Contains(c, order.TargetCoast) {
+				return nil, OutcomeBadCoast
+			}
+		}
+		return nil, OutcomeSuccess
+	case Waive:
+		if a.buildCount[country] <= 0 {
+			return nil, OutcomeNoBuilds
 		}
 		return nil, OutcomeSuccess
 	default:
@@ -267,23 +480,58 @@ func (a *Arena) doBuildPhase(country string, order Order) (*Occupancy, Outcome)
 	}
 }
 
+// setOutcome changes the outcome of the order given to a unit.
+func (a *Arena) setOutcome(unit *Occupancy, outcome Outcome) {
+	uo := a.unitOrders[unit]
+	uo.outcome = outcome
+	if _, ok := a.countryOrders[unit.country][uo.order]; ok {
+		a.countryOrders[unit.country][uo.order] = outcome
+	}
+}
+
 func (a *Arena) do(country string, order Order, add bool) Outcome {
 	var (
-		o Outcome
-		u *Occupancy
+		o       Outcome
+		u       *Occupancy
+		illegal bool
 	)
 	switch {
 	case a.game.phase.Move():
 		u, o = a.doMovePhase(country, order)
+		if u != nil {
+			// Outcomes of moves depend on all other orders.
+			illegal = o != OutcomeSuccess
+			a.unitOrders[u] = &unitOrder{order, o, illegal}
+			a.adjudicate()
+			o = a.unitOrders[u].outcome
+			if !add {
+				delete(a.unitOrders, u)
+				a.adjudicate()
+			}
+		}
 	case a.game.phase.Retreat():
 		u, o = a.doRetreatPhase(country, order)
+		if add && order.Kind() == MoveRetreat && (o == OutcomeSuccess || o == OutcomeStandoff) {
+			rs := append(a.retreaters[order.Target], u)
+			a.retreaters[order.Target] = rs
+			for _, r := range rs[:len(rs)-1] {
+				a.setOutcome(r, OutcomeStandoff)
+			}
+		}
 	case a.game.phase == Winter:
 		u, o = a.doBuildPhase(country, order)
 		if add && o == OutcomeSuccess {
-			if order.Kind() == Build {
-				a.builds[order.Target] = build{order.Build, order.TargetCoast}
+			switch order.Kind() {
+			case Waive:
+				a.waived[country] = true
+			case Build:
+				coast := order.TargetCoast
+				if cs := order.Target.coasts; order.Build == Fleet && coast == "" && len(cs) > 0 {
+					coast = cs[0]
+				}
+				a.builds[order.Target] = build{order.Build, coast, country}
 				a.buildCount[country]--
-			} else {
+			default:
 				a.buildCount[country]++
 			}
 		}
@@ -291,7 +539,7 @@ func (a *Arena) do(country string, order Order, add bool) Outcome {
 	if add {
 		a.countryOrders[country][order] = o
 		if u != nil {
-			a.unitOrders[u] = &unitOrder{order, o}
+			a.unitOrders[u] = &unitOrder{order, o, illegal}
 		}
 	}
 	return o
@@ -314,7 +562,7 @@ func (a *Arena) undo(country string, order Order, outcome Outcome) {
 		}
 		unit := a.game.Unit(order.Unit)
 		delete(a.unitOrders, unit)
-		// TODO
+		a.adjudicate()
 	case a.game.phase.Retreat():
 		if !outcomeAssigned(outcome) {
 			return
@@ -332,19 +580,22 @@ func (a *Arena) undo(country string, order Order, outcome Outcome) {
 			}
 		}
 		if len(rs) == 1 {
-			a.unitOrders[rs[0]].outcome = OutcomeSuccess
+			a.setOutcome(rs[0], OutcomeSuccess)
 		}
 	case a.game.phase == Winter:
 		if outcome != OutcomeSuccess {
 			return
 		}
-		if order.Kind() == HoldDisband {
+		switch order.Kind() {
+		case HoldDisband:
 			unit := a.game.Unit(order.Unit)
 			delete(a.unitOrders, unit)
-			a.buildCount[country]++
-		} else {
-			delete(a.builds, order.Target)
 			a.buildCount[country]--
+		case Build:
+			delete(a.builds, order.Target)
+			a.buildCount[country]++
+		case Waive:
+			delete(a.waived, country)
 		}
 	}
 }
@@ -356,14 +607,19 @@ func (a *Arena) undo(country string, order Order, outcome Outcome) {
 //
 // If the order already exists, it gets the outcome of that order.
 func (a *Arena) Query(country string, order Order) Outcome {
+	if outcome, ok := a.countryOrders[country][order]; ok {
+		return outcome
+	}
 	return a.do(country, order, false)
 }
 
-// Orders is all orders given by a country.
+// Orders is all orders given by a country, in the order they were added.
 func (a *Arena) Orders(country string) []Order {
 	orders := make([]Order, 0, len(a.countryOrders[country]))
-	for o := range a.countryOrders[country] {
-		orders = append(orders, o)
+	for _, g := range a.given {
+		if g.country == country {
+			orders = append(orders, g.order)
+		}
 	}
 	return orders
 }
@@ -459,12 +715,27 @@ func (a *Arena) BuildCountLeft(country string) int {
 	return a.buildCount[country]
 }
 
+// Waived is how many builds a country is leaving unused on purpose,
+// by a waive order. Builds left unused without one are not counted.
+func (a *Arena) Waived(country string) int {
+	if !a.waived[country] {
+		return 0
+	}
+	return max(a.buildCount[country], 0)
+}
+
 // Add processes and saves a country's order.
+//
+// Adding an order that has already been added has no effect.
 func (a *Arena) Add(country string, order Order) (Outcome, error) {
 	if !slices.Contains(a.game.board.countries, country) {
 		return 0, errors.New("invalid country")
 		// TODO country parsing
 	}
+	if outcome, ok := a.countryOrders[country][order]; ok {
+		return outcome, nil
+	}
+	a.given = append(a.given, givenOrder{country, order})
 	return a.do(country, order, true), nil
 }
 
@@ -473,6 +744,9 @@ func (a *Arena) Remove(country string, order Order) {
 	if outcome, ok := a.countryOrders[country][order]; ok {
 		a.undo(country, order, outcome)
 		delete(a.countryOrders[country], order)
+		a.given = slices.DeleteFunc(a.given, func(g givenOrder) bool {
+			return g.country == country && g.order == order
+		})
 	}
 }
 
@@ -481,7 +755,21 @@ func (a *Arena) Clear(country string) {
 	for order, outcome := range a.countryOrders[country] {
 		a.undo(country, order, outcome)
 	}
-	delete(a.countryOrders, country)
+	clear(a.countryOrders[country])
+	a.given = slices.DeleteFunc(a.given, func(g givenOrder) bool {
+		return g.country == country
+	})
+}
+
+// SetParadoxRule changes how convoy paradoxes are decided for this arena
+// only, updating the outcomes of the orders given so far. The arena starts
+// with the game's rule (see [Game.Rules]).
+func (a *Arena) SetParadoxRule(rule ParadoxRule) {
+	if !a.game.phase.Move() {
+		return
+	}
+	a.rules.Paradox = rule
+	a.adjudicate()
 }
 
 // FillIn gives the default orders to unordered units.
@@ -507,53 +795,81 @@ func (a *Arena) FillIn() {
 	}
 }
 
+// landingCoast is the coast a unit ends up on after moving or retreating
+// to the target of its order.
+func (a *Arena) landingCoast(unit *Occupancy, order Order) string {
+	if unit.unit != Fleet {
+		return ""
+	}
+	cs := a.game.board.Connection(unit.province, order.Target).toCoasts
+	if len(cs) == 0 {
+		return ""
+	}
+	if order.TargetCoast == "" {
+		return cs[0]
+	}
+	return order.TargetCoast
+}
+
 // Go creates a new game state following the adjudication
 // of the orders added to the arena.
 func (a *Arena) Go() *Game {
+	// Countries that gave no orders, before defaults are filled in.
+	nmr := make(map[string]bool)
+	if a.game.phase.Move() && a.rules.DisbandOnNMR {
+		for c, orders := range a.countryOrders {
+			nmr[c] = len(orders) == 0
+		}
+	}
 	a.FillIn()
 	next := &Game{
-		board:     a.game.board,
-		year:      a.game.year,
-		phase:     a.game.phase,
-		units:     make(map[*Province]*Occupancy),
-		centers:   maps.Clone(a.game.centers),
-		dislodged: make(map[*Province]*Occupancy),
-		contests:  make(map[*Province]bool),
+		board:   a.game.board,
+		year:    a.game.year,
+		phase:   a.game.phase,
+		units:   make(map[*Province]*Occupancy),
+		centers: maps.Clone(a.game.centers),
+		rules:   a.game.rules,
+		suggest: a.game.suggest,
+	}
+	next.resetRetreats()
+	// TODO skip empty retreat and build phases. Skip() method?
+	// Advance phase and year.
+	next.phase++
+	if next.phase > Winter {
+		next.phase = Spring
+		next.year++
 	}
 	// Apply successful orders.
 	switch {
 	case a.game.phase.Move():
 		for u := range a.game.AllUnits() {
-			uo := a.unitOrders[u]
-			if uo.outcome == OutcomeDislodged {
-				next.AddDislodged(u.province, u.coast, u.unit, u.country, a.attackers[u])
-			} else if uo.order.Kind() == MoveRetreat && uo.outcome == OutcomeSuccess {
-				coast := uo.order.TargetCoast
-				cs := a.game.board.Connection(u.province, uo.order.Target).toCoasts
-				if coast == "" && len(cs) > 0 {
-					coast = cs[0]
-				}
-				if len(cs) == 0 {
-					coast = ""
-				}
-				next.SetUnit(uo.order.Target, coast, u.unit, u.country)
+			if nmr[u.country] {
+				// Disbanded only now, having held for the phase.
+				continue
+			}
+			if from, ok := a.attackers[u]; ok {
+				next.AddDislodged(u.province, u.coast, u.unit, u.country, from)
+			} else if to, ok := a.moving[u]; ok {
+				coast := a.landingCoast(u, a.unitOrders[u].order)
+				next.SetUnit(to, coast, u.unit, u.country)
+			} else {
+				next.SetUnit(u.province, u.coast, u.unit, u.country)
 			}
 		}
+		for p := range a.contested {
+			next.BlockRetreat(p)
+		}
 	case a.game.phase.Retreat():
+		for u := range a.game.AllUnits() {
+			next.SetUnit(u.province, u.coast, u.unit, u.country)
+		}
 		for u := range a.game.AllDislodged() {
 			uo := a.unitOrders[u]
 			if uo.order.Kind() != MoveRetreat || uo.outcome != OutcomeSuccess {
 				// Order failed or unit deliberately disbanded.
 				continue
 			}
-			coast := uo.order.TargetCoast
-			cs := a.game.board.Connection(u.province, uo.order.Target).toCoasts
-			if coast == "" && len(cs) > 0 {
-				coast = cs[0]
-			}
-			if len(cs) == 0 {
-				coast = ""
-			}
+			coast := a.landingCoast(u, uo.order)
 			next.SetUnit(uo.order.Target, coast, u.unit, u.country)
 		}
 	case a.game.phase == Winter:
@@ -564,10 +880,16 @@ func (a *Arena) Go() *Game {
 			if bc >= 0 {
 				continue
 			}
-			// Country has disbands unaccounted for; disband farthest-first.
-			units := a.game.FarthestUnits(c)
-			for i := range -bc {
-				cd[units[i]] = true
+			// Country has disbands unaccounted for; disband farthest-first,
+			// skipping units already ordered to disband.
+			for _, u := range a.game.FarthestUnits(c) {
+				if bc == 0 {
+					break
+				}
+				if _, ok := a.unitOrders[u]; !ok {
+					cd[u] = true
+					bc++
+				}
 			}
 		}
 		// Exclude disbanded units.
@@ -584,7 +906,7 @@ func (a *Arena) Go() *Game {
 		}
 		// Add built units.
 		for p, b := range a.builds {
-			next.SetUnit(p, b.coast, b.unit, p.country)
+			next.SetUnit(p, b.coast, b.unit, b.country)
 		}
 	}
 	// End of fall: add centers.
@@ -595,12 +917,5 @@ func (a *Arena) Go() *Game {
 			}
 		}
 	}
-	// TODO skip empty retreat and build phases. Skip() method?
-	// Advance phase and year.
-	next.phase++
-	if next.phase > Winter {
-		next.phase = Spring
-		next.year++
-	}
 	return next
 }
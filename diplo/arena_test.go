@@ -0,0 +1,41 @@
+package diplo
+
+import "testing"
+
+// mustParseOrder parses an order for a country, failing the test if it does not parse.
+func mustParseOrder(t *testing.T, g *Game, country, text string) Order {
+	t.Helper()
+	o, err := g.ParseOrder(text, country)
+	if err != nil {
+		t.Fatalf("ParseOrder(%q): %v", text, err)
+	}
+	return *o
+}
+
+func TestDisbandOnNMR(t *testing.T) {
+	for _, rule := range []bool{false, true} {
+		g, err := ParseNotation(StandardBoard, "S1901M F:ABur;G:AMun,ABer;SC:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.SetRules(RuleSet{DisbandOnNMR: rule})
+		a := g.Arena()
+		move := mustParseOrder(t, g, "France", "A Bur - Mun")
+		a.Add("France", move)
+		next := a.Go()
+		// Germany's units defend Munich either way.
+		if outcome := a.Outcomes("France")[move]; outcome != OutcomeWeak {
+			t.Errorf("DisbandOnNMR %v: A Bur - Mun => %v, want Weak", rule, outcome)
+		}
+		want := 2
+		if rule {
+			want = 0
+		}
+		if got := next.UnitCount("Germany"); got != want {
+			t.Errorf("DisbandOnNMR %v: Germany has %d units after the phase, want %d", rule, got, want)
+		}
+		if u := next.Unit(StandardBoard.ParseProvince("Bur")[0]); u == nil || u.Country() != "France" {
+			t.Errorf("DisbandOnNMR %v: A Bur did not stay in Burgundy", rule)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package diplo
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"slices"
 	"strings"
 )
@@ -20,6 +23,11 @@ type BuilderProvince struct {
 	// There must be at least one. No two provinces may share any abbreviations.
 	// Abbreviations should consist only of uppercase and lowercase characters. (Examples: WES, StP, not Wes-Med, not St.P)
 	Abbreviations []string
+	// Aliases is a list of other names for the province, like "Saint Petersburg",
+	// accepted wherever province names are parsed.
+	//
+	// No two provinces may share an alias, and an alias may not be another province's name.
+	Aliases []string `json:",omitempty"`
 	// Terrain is the province's terrain type.
 	Terrain Terrain
 	// Coasts is the symbols shown for distinct coasts a coastal province may have.
@@ -67,10 +75,23 @@ type BuilderConnection struct {
 	Coastal bool `json:",omitempty"`
 }
 
+// BuilderUnit is a unit in the starting position of the board.
+type BuilderUnit struct {
+	// Country is the country that owns the unit.
+	Country string
+	// Unit is the unit type, "Army" or "Fleet" (or "A" or "F").
+	Unit string
+	// Province is the name or an abbreviation of the province the unit starts in.
+	Province string
+	// Coast is the coast a Fleet starts on, if the province has named coasts.
+	Coast string `json:",omitempty"`
+}
+
 // Builder allows for the construction of custom boards. The zero-value is an empty builder
 // ready to use; you can add countries, provinces, and connections directly.
 //
-// It can be deserialized from JSON.
+// It can be deserialized from JSON (see [LoadBoard]). The parser functions cannot;
+// use [Builder.CountryAliases] and [Builder.CoastAliases] instead.
 type Builder struct {
 	// Countries is a list of the names of countries on the board, capitalized and formatted
 	// as you want them to be displayed in-game.
@@ -81,16 +102,35 @@ type Builder struct {
 	//
 	// Connections should only be provided once per pair of provinces.
 	Connections []BuilderConnection
+	// Units is the starting position, one unit per entry. It may be left empty
+	// for boards whose games are set up by hand; see [NewStartingGame].
+	Units []BuilderUnit `json:",omitempty"`
+	// Geometry is where each province is drawn, for pictures of games on the
+	// board (see [Game.WriteSVG]). It may be left out.
+	Geometry *Geometry `json:",omitempty"`
+	// CountryAliases gives other names accepted for each country, like
+	// "Hungary" for "Austria". Names and aliases are matched ignoring case.
+	//
+	// When this is set and CountryParser is not, the country parser is
+	// generated from it; countries without an entry are parsed by name only.
+	CountryAliases map[string][]string `json:",omitempty"`
+	// CoastAliases gives other names accepted for each coast, like "North"
+	// for "NC". Names and aliases are matched ignoring case.
+	//
+	// When this is set and CoastParser is not, the coast parser is generated
+	// from it, and only the coasts it names may be used.
+	CoastAliases map[string][]string `json:",omitempty"`
 	// CoastParser interprets string representations of coast names.
 	//
 	// If unset, the default coast parser can parse NC, EC, SC, and WC; you will
-	// need to implement a custom parser if your map uses other coast names.
+	// need to implement a custom parser (or give [Builder.CoastAliases]) if your
+	// map uses other coast names.
 	CoastParser func(string) (string, bool) `json:"-"`
 	// CountryParser interprets string representations of country names.
 	//
 	// If unset, the default country parser can parse the names of the standard
 	// Diplomacy game's countries; you will need to implement a custom parser
-	// if your map uses custom countries.
+	// (or give [Builder.CountryAliases]) if your map uses custom countries.
 	CountryParser func(string) (string, bool) `json:"-"`
 }
 
@@ -164,12 +204,80 @@ func (c *Connection) valid() error {
 	return nil
 }
 
+// aliasParser generates a parser accepting each name or any of its aliases,
+// ignoring case. No two names may share an alias.
+func aliasParser(kind string, aliases map[string][]string) (func(string) (string, bool), error) {
+	lookup := make(map[string]string)
+	add := func(alias, name string) error {
+		key := strings.ToLower(strings.TrimSpace(alias))
+		if key == "" {
+			return fmt.Errorf("empty alias for %s %s", kind, name)
+		}
+		if other, ok := lookup[key]; ok && other != name {
+			return fmt.Errorf("%s alias %s is used for both %s and %s", kind, alias, other, name)
+		}
+		lookup[key] = name
+		return nil
+	}
+	for name := range aliases {
+		if err := add(name, strings.TrimSpace(name)); err != nil {
+			return nil, err
+		}
+	}
+	for name, as := range aliases {
+		for _, alias := range as {
+			if err := add(alias, strings.TrimSpace(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return func(s string) (string, bool) {
+		name, ok := lookup[strings.ToLower(strings.TrimSpace(s))]
+		return name, ok
+	}, nil
+}
+
+// LoadBoard reads a [Builder] as JSON and builds its board. Unknown fields
+// are an error, so that misspelled fields are not silently ignored.
+func LoadBoard(r io.Reader) (*Board, error) {
+	var b Builder
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&b); err != nil {
+		return nil, fmt.Errorf("reading board: %w", err)
+	}
+	return b.Build()
+}
+
 func (b *Builder) Build() (*Board, error) {
 	board := &Board{
 		coastParser:   b.CoastParser,
 		countryParser: b.CountryParser,
 		connections:   make(map[endpoints]*Connection),
 	}
+	if board.countryParser == nil && b.CountryAliases != nil {
+		aliases := maps.Clone(b.CountryAliases)
+		for _, c := range b.Countries {
+			if _, ok := aliases[c]; !ok {
+				aliases[c] = nil
+			}
+		}
+		for c := range b.CountryAliases {
+			if !slices.Contains(b.Countries, c) {
+				return nil, fmt.Errorf("aliases given for unknown country %s", c)
+			}
+		}
+		var err error
+		if board.countryParser, err = aliasParser("country", aliases); err != nil {
+			return nil, err
+		}
+	}
+	if board.coastParser == nil && b.CoastAliases != nil {
+		var err error
+		if board.coastParser, err = aliasParser("coast", b.CoastAliases); err != nil {
+			return nil, err
+		}
+	}
 	for _, c := range b.Countries {
 		var ok bool
 		c, ok = board.ParseCountry(c)
@@ -183,6 +291,13 @@ func (b *Builder) Build() (*Board, error) {
 	if len(board.countries) == 0 {
 		return nil, errors.New("no valid countries")
 	}
+	// Aliases are checked against every province's name, not only the
+	// provinces before them.
+	names := make(map[string]string)   // by simplified name
+	aliases := make(map[string]string) // province names by simplified alias
+	for _, p := range b.Provinces {
+		names[simplify(p.Name)] = strings.TrimSpace(p.Name)
+	}
 	for _, p := range b.Provinces {
 		name := strings.TrimSpace(p.Name)
 		if name == "" {
@@ -206,6 +321,21 @@ func (b *Builder) Build() (*Board, error) {
 			}
 			p.Abbreviations[i] = abbr
 		}
+		for i, alias := range p.Aliases {
+			alias = strings.TrimSpace(alias)
+			key := simplify(alias)
+			if key == "" {
+				return nil, fmt.Errorf("empty alias for %s", name)
+			}
+			if other, ok := names[key]; ok && other != name {
+				return nil, fmt.Errorf("duplicate alias %s", alias)
+			}
+			if other, ok := aliases[key]; ok && other != name {
+				return nil, fmt.Errorf("duplicate alias %s", alias)
+			}
+			aliases[key] = name
+			p.Aliases[i] = alias
+		}
 		if p.Country != "" {
 			p.Center = true
 			country, ok := board.ParseCountry(p.Country)
@@ -230,6 +360,7 @@ func (b *Builder) Build() (*Board, error) {
 		board.provinces = append(board.provinces, &Province{
 			name:    name,
 			abbrs:   p.Abbreviations,
+			aliases: p.Aliases,
 			terrain: p.Terrain,
 			coasts:  p.Coasts,
 			center:  p.Center,
@@ -281,5 +412,40 @@ func (b *Builder) Build() (*Board, error) {
 		}
 		board.connections[e] = connection
 	}
+	if b.Geometry != nil {
+		geometry, err := board.resolveGeometry(b.Geometry)
+		if err != nil {
+			return nil, fmt.Errorf("geometry: %w", err)
+		}
+		board.geometry = geometry
+	}
+	setup := NewGame(board)
+	for _, u := range b.Units {
+		unit, ok := ParseUnit(strings.TrimSpace(u.Unit))
+		if !ok {
+			return nil, fmt.Errorf("unknown unit type %s in %s", u.Unit, u.Province)
+		}
+		ps := board.ParseProvince(u.Province)
+		if len(ps) != 1 {
+			return nil, fmt.Errorf("unknown province %s for starting unit", u.Province)
+		}
+		country, ok := board.ParseCountry(u.Country)
+		if !ok {
+			return nil, fmt.Errorf("unknown country %s for unit in %s", u.Country, ps[0].name)
+		}
+		coast := ""
+		if u.Coast != "" {
+			if coast, ok = board.ParseCoast(u.Coast); !ok {
+				return nil, fmt.Errorf("unknown coast %s for unit in %s", u.Coast, ps[0].name)
+			}
+		}
+		if setup.Unit(ps[0]) != nil {
+			return nil, fmt.Errorf("two starting units in %s", ps[0].name)
+		}
+		if err := setup.SetUnit(ps[0], coast, unit, country); err != nil {
+			return nil, err
+		}
+		board.units = append(board.units, setup.Unit(ps[0]))
+	}
 	return board, nil
 }
@@ -0,0 +1,121 @@
+package diplo
+
+import (
+	"strings"
+	"testing"
+)
+
+// testBoardJSON is a small board using each part of the JSON board format.
+const testBoardJSON = `{
+	"Countries": ["Atlantis", "Mu"],
+	"CountryAliases": {"Atlantis": ["Atlantean"]},
+	"CoastAliases": {"NC": ["North"], "SC": ["South"]},
+	"Provinces": [
+		{"Name": "Poseidonia", "Abbreviations": ["Pos"], "Terrain": "Coastal", "Coasts": ["North", "SC"], "Country": "Atlantean"},
+		{"Name": "Lemuria", "Abbreviations": ["Lem"], "Aliases": ["Lemuria Minor"], "Terrain": "Coastal", "Country": "Mu"},
+		{"Name": "Hills", "Abbreviations": ["Hil"], "Terrain": "Inland"},
+		{"Name": "Deep Sea", "Abbreviations": ["DEE"], "Terrain": "Water"},
+		{"Name": "Shallows", "Abbreviations": ["SHA"], "Terrain": "Water"}
+	],
+	"Connections": [
+		{"From": "DEE", "To": "Pos", "ToCoasts": ["North"]},
+		{"From": "SHA", "To": "Pos", "ToCoasts": ["South"]},
+		{"From": "DEE", "To": "SHA"},
+		{"From": "DEE", "To": "Lem"},
+		{"From": "SHA", "To": "Lem"},
+		{"From": "Pos", "To": "Lem", "FromCoasts": ["SC"], "Coastal": true},
+		{"From": "Hil", "ToAll": ["Pos", "Lem"]}
+	],
+	"Units": [
+		{"Country": "Atlantean", "Unit": "F", "Province": "Pos", "Coast": "North"},
+		{"Country": "Mu", "Unit": "Army", "Province": "Lemuria"}
+	],
+	"Geometry": {
+		"Width": 100, "Height": 100,
+		"Provinces": {
+			"Poseidonia": {"Polygon": [[0, 0], [20, 0], [20, 20], [0, 20]], "Unit": [10, 10], "Coasts": {"North": [10, 2], "SC": [10, 18]}},
+			"Lem": {"Polygon": [[80, 0], [100, 0], [100, 20], [80, 20]], "Unit": [90, 10]},
+			"Hills": {"Polygon": [[40, 0], [60, 0], [60, 20]], "Unit": [50, 10]},
+			"DEE": {"Polygon": [[0, 40], [100, 40], [100, 60], [0, 60]], "Unit": [50, 50]},
+			"SHA": {"Polygon": [[0, 80], [100, 80], [100, 100], [0, 100]], "Unit": [50, 90]}
+		}
+	}
+}`
+
+func TestLoadBoard(t *testing.T) {
+	b, err := LoadBoard(strings.NewReader(testBoardJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := b.ParseCountry("atlantean"); !ok || c != "Atlantis" {
+		t.Errorf("ParseCountry(atlantean) = %q, %v", c, ok)
+	}
+	if c, ok := b.ParseCoast("south"); !ok || c != "SC" {
+		t.Errorf("ParseCoast(south) = %q, %v", c, ok)
+	}
+	pos, lem := b.Province("Poseidonia"), b.Province("Lemuria")
+	if got := pos.Coasts(); len(got) != 2 || got[0] != "NC" || got[1] != "SC" {
+		t.Errorf("Poseidonia coasts = %v, want [NC SC]", got)
+	}
+	if ps := b.ParseProvince("Lemuria Minor"); len(ps) != 1 || ps[0] != lem {
+		t.Errorf("alias Lemuria Minor parses as %v", ps)
+	}
+	if !b.Connects(b.Province("Deep Sea"), pos, "", "NC") || b.Connects(b.Province("Deep Sea"), pos, "", "SC") {
+		t.Error("Deep Sea should reach only the north coast of Poseidonia")
+	}
+
+	var units []*Occupancy
+	for u := range b.StartingUnits() {
+		units = append(units, u)
+	}
+	if len(units) != 2 {
+		t.Fatalf("%d starting units, want 2", len(units))
+	}
+	if u := units[0]; u.Country() != "Atlantis" || u.Unit() != Fleet || u.Province() != pos || u.coast != "NC" {
+		t.Errorf("first unit is %s %v in %s/%s", u.Country(), u.Unit(), u.Province().Name(), u.coast)
+	}
+	if u := units[1]; u.Country() != "Mu" || u.Unit() != Army || u.Province() != lem {
+		t.Errorf("second unit is %s %v in %s", u.Country(), u.Unit(), u.Province().Name())
+	}
+	if _, err := NewStartingGame(b); err != nil {
+		t.Errorf("NewStartingGame: %v", err)
+	}
+
+	geo := b.Geometry()
+	if geo == nil {
+		t.Fatal("no geometry")
+	}
+	if len(geo.Provinces) != 5 {
+		t.Errorf("geometry has %d provinces, want 5", len(geo.Provinces))
+	}
+	if pt := geo.unitPoint(pos, "NC"); pt != (Point{10, 2}) {
+		t.Errorf("Poseidonia/NC drawn at %v, want [10 2]", pt)
+	}
+	if pt := geo.unitPoint(lem, ""); pt != (Point{90, 10}) {
+		t.Errorf("Lemuria drawn at %v, want [90 10]", pt)
+	}
+}
+
+func TestLoadBoardErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		err      string
+	}{
+		{"unknown field", `"Provinces":`, `"Provences": [], "Provinces":`, `unknown field "Provences"`},
+		{"alias of a later province", `"Aliases": ["Lemuria Minor"]`, `"Aliases": ["Deep Sea"]`, "duplicate alias Deep Sea"},
+		{"alias of an earlier province", `"Aliases": ["Lemuria Minor"]`, `"Aliases": ["Poseidonia"]`, "duplicate alias Poseidonia"},
+		{"unknown country alias", `{"Atlantis": ["Atlantean"]}`, `{"Lyonesse": ["Atlantean"]}`, "unknown country Lyonesse"},
+		{"unknown coast", `"Coast": "North"`, `"Coast": "East"`, "unknown coast East"},
+		{"geometry", `"Width": 100`, `"Width": 0`, "geometry: no size"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(testBoardJSON, tt.old) {
+			t.Fatalf("%s: board has no %s", tt.name, tt.old)
+		}
+		_, err := LoadBoard(strings.NewReader(strings.Replace(testBoardJSON, tt.old, tt.new, 1)))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: LoadBoard error %v, want one containing %q", tt.name, err, tt.err)
+		}
+	}
+}
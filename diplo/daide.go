@@ -0,0 +1,1020 @@
+package diplo
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// daideProvinces is the tokens of the provinces of the standard board in the
+// DAIDE protocol, preferred when a province has one as an abbreviation.
+var daideProvinces = strings.Fields(`
+	ADR AEG ALB ANK APU ARM BAL BAR BEL BER BLA BOH BRE BUD BUL BUR CLY CON
+	DEN EAS ECH EDI FIN GAL GAS GOB GOL GRE HEL HOL ION IRI KIE LON LVN LVP
+	MAO MAR MOS MUN NAF NAO NAP NTH NWG NWY PAR PIC PIE POR PRU ROM RUH RUM
+	SER SEV SIL SKA SMY SPA STP SWE SYR TRI TUN TUS TYR TYS UKR VEN VIE WAL
+	WAR WES YOR
+`)
+
+var daideSeasons = [...]string{
+	Spring:         "SPR",
+	SpringRetreats: "SUM",
+	Fall:           "FAL",
+	FallRetreats:   "AUT",
+	Winter:         "WIN",
+}
+
+// DAIDECountry is the token for a country in the DAIDE protocol: the first
+// three letters of its name, like "FRA".
+func DAIDECountry(country string) string {
+	return strings.ToUpper(country[:min(3, len(country))])
+}
+
+// DAIDEProvince is the token for a province in the DAIDE protocol: its
+// abbreviation that is a standard token, if any, like "GOL" rather than "LYO"
+// for the Gulf of Lyon, or else its first three-letter abbreviation that is
+// not also a country's token, like "ECH" rather than "ENG" for the English
+// Channel.
+func (b *Board) DAIDEProvince(p *Province) string {
+	for _, abbr := range p.abbrs {
+		if token := strings.ToUpper(abbr); slices.Contains(daideProvinces, token) {
+			return token
+		}
+	}
+	for _, abbr := range p.abbrs {
+		token := strings.ToUpper(abbr)
+		if len(token) != 3 {
+			continue
+		}
+		if slices.ContainsFunc(b.countries, func(c string) bool {
+			return DAIDECountry(c) == token
+		}) {
+			continue
+		}
+		return token
+	}
+	return strings.ToUpper(p.abbrs[0])
+}
+
+// daideCoast is the token for a coast in the DAIDE protocol, like "NCS".
+func daideCoast(coast string) string {
+	switch coast = strings.ToUpper(coast); coast {
+	case "NC", "EC", "SC", "WC":
+		return coast + "S"
+	default:
+		return coast
+	}
+}
+
+func daideUnit(u Unit) string {
+	if u == Fleet {
+		return "FLT"
+	}
+	return "AMY"
+}
+
+// daideExpr is a DAIDE token, or a list of expressions in parentheses.
+type daideExpr struct {
+	token string
+	list  []daideExpr
+}
+
+func (e daideExpr) String() string {
+	if e.token != "" {
+		return e.token
+	}
+	s := make([]string, len(e.list))
+	for i, x := range e.list {
+		s[i] = x.String()
+	}
+	return "( " + strings.Join(s, " ") + " )"
+}
+
+// parseDAIDE splits DAIDE text into expressions. Case is ignored.
+func parseDAIDE(text string) ([]daideExpr, error) {
+	text = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(strings.ToUpper(text))
+	stack := [][]daideExpr{nil}
+	for _, token := range strings.Fields(text) {
+		switch token {
+		case "(":
+			stack = append(stack, nil)
+		case ")":
+			if len(stack) == 1 {
+				return nil, errors.New("unbalanced ')'")
+			}
+			list := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			stack[len(stack)-1] = append(stack[len(stack)-1], daideExpr{list: list})
+		default:
+			stack[len(stack)-1] = append(stack[len(stack)-1], daideExpr{token: token})
+		}
+	}
+	if len(stack) > 1 {
+		return nil, errors.New("unbalanced '('")
+	}
+	return stack[0], nil
+}
+
+// daideMessage parses DAIDE text that starts with a command, like "NOW",
+// giving the expressions after it.
+func daideMessage(text, command string) ([]daideExpr, error) {
+	exprs, err := parseDAIDE(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(exprs) == 0 || exprs[0].token != command {
+		return nil, fmt.Errorf("not a %s message", command)
+	}
+	return exprs[1:], nil
+}
+
+// parseDAIDECountry finds the country with a token.
+func (b *Board) parseDAIDECountry(e daideExpr) (string, error) {
+	for _, c := range b.countries {
+		if DAIDECountry(c) == e.token {
+			return c, nil
+		}
+	}
+	return "", &UnknownCountryError{e.String()}
+}
+
+// parseDAIDEProvince finds the province with a token, or else with the token as
+// an abbreviation.
+func (b *Board) parseDAIDEProvince(e daideExpr) (*Province, error) {
+	if e.token == "" {
+		return nil, fmt.Errorf("expected province, got %s", e)
+	}
+	for _, p := range b.provinces {
+		if b.DAIDEProvince(p) == e.token {
+			return p, nil
+		}
+	}
+	if p := b.abbreviated(e.token); p != nil {
+		return p, nil
+	}
+	return nil, &UnknownProvinceError{Name: e.token}
+}
+
+// parseDAIDELocation finds a province, and the coast if given, like "( STP NCS )".
+func (b *Board) parseDAIDELocation(e daideExpr) (*Province, string, error) {
+	if e.token != "" {
+		p, err := b.parseDAIDEProvince(e)
+		return p, "", err
+	}
+	if len(e.list) != 2 || e.list[1].token == "" {
+		return nil, "", fmt.Errorf("expected province and coast, got %s", e)
+	}
+	p, err := b.parseDAIDEProvince(e.list[0])
+	if err != nil {
+		return nil, "", err
+	}
+	for _, c := range p.coasts {
+		if daideCoast(c) == e.list[1].token {
+			return p, c, nil
+		}
+	}
+	return nil, "", &UnknownCoastError{p.name, e.list[1].token}
+}
+
+// parseDAIDEUnit interprets a unit, like "( RUS FLT ( STP NCS ) )".
+func (b *Board) parseDAIDEUnit(e daideExpr) (string, Unit, *Province, string, error) {
+	if len(e.list) != 3 {
+		return "", 0, nil, "", fmt.Errorf("expected unit, got %s", e)
+	}
+	country, err := b.parseDAIDECountry(e.list[0])
+	if err != nil {
+		return "", 0, nil, "", err
+	}
+	var unit Unit
+	switch e.list[1].token {
+	case "AMY":
+		unit = Army
+	case "FLT":
+		unit = Fleet
+	default:
+		return "", 0, nil, "", fmt.Errorf("unknown unit type %s", e.list[1])
+	}
+	p, coast, err := b.parseDAIDELocation(e.list[2])
+	if err != nil {
+		return "", 0, nil, "", err
+	}
+	return country, unit, p, coast, nil
+}
+
+// daideSeasonError is an order given in the wrong phase.
+type daideSeasonError struct {
+	verb  string
+	phase Phase
+}
+
+func (e *daideSeasonError) Error() string {
+	return fmt.Sprintf("%s order in %v", e.verb, e.phase)
+}
+
+// ParseDAIDEOrder interprets an order written in the tokens of the DAIDE
+// protocol, like "( ( ENG FLT LON ) MTO NTH )", giving the country of the
+// unit ordered. The outer parentheses may be left out. Case is ignored.
+//
+// HLD, MTO, SUP, CVY, and CTO are accepted in move phases, RTO and DSB in
+// retreat phases, and BLD, REM, and WVE, like "( FRA WVE )", in [Winter].
+// The chain of fleets given to a CTO is not checked; the army goes by any
+// convoy, and a CTO to a neighboring province sets [Order.ViaConvoy].
+//
+// Provinces are found by their DAIDE tokens (see [Board.DAIDEProvince]) or their
+// abbreviations. Whether the ordered unit exists is left to [Arena.Add].
+func (g *Game) ParseDAIDEOrder(text string) (string, *Order, error) {
+	exprs, err := parseDAIDE(text)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(exprs) == 1 && exprs[0].token == "" {
+		exprs = exprs[0].list
+	}
+	if len(exprs) < 2 || exprs[1].token == "" {
+		return "", nil, fmt.Errorf("malformed order %s", strings.TrimSpace(text))
+	}
+	b := g.board
+	verb := exprs[1].token
+	if verb == "WVE" {
+		country, err := b.parseDAIDECountry(exprs[0])
+		if err != nil {
+			return "", nil, err
+		}
+		if g.phase != Winter || len(exprs) != 2 {
+			return "", nil, fmt.Errorf("malformed order %s", strings.TrimSpace(text))
+		}
+		o := OrderWaive()
+		return country, &o, nil
+	}
+	country, unit, p, coast, err := b.parseDAIDEUnit(exprs[0])
+	if err != nil {
+		return "", nil, err
+	}
+	args := exprs[2:]
+	var o Order
+	phase := Winter
+	switch {
+	case verb == "HLD" && len(args) == 0:
+		phase, o = Spring, OrderHoldDisband(p)
+	case verb == "MTO" && len(args) == 1, verb == "RTO" && len(args) == 1:
+		target, targetCoast, err := b.parseDAIDELocation(args[0])
+		if err != nil {
+			return "", nil, err
+		}
+		o = OrderMoveRetreat(p, target, targetCoast)
+		if phase = Spring; verb == "RTO" {
+			phase = SpringRetreats
+		}
+	case verb == "CTO" && len(args) == 3 && args[1].token == "VIA" && args[2].token == "":
+		target, err := b.parseDAIDEProvince(args[0])
+		if err != nil {
+			return "", nil, err
+		}
+		for _, e := range args[2].list {
+			if _, err := b.parseDAIDEProvince(e); err != nil {
+				return "", nil, err
+			}
+		}
+		// A move across water can only go by convoy; one to a neighbor
+		// must say so.
+		phase, o = Spring, OrderMoveRetreat(p, target, "")
+		if b.Connection(p, target) != nil {
+			o = OrderMoveViaConvoy(p, target)
+		}
+	case verb == "SUP" && (len(args) == 1 || len(args) == 3 && args[1].token == "MTO"):
+		_, _, recipient, _, err := b.parseDAIDEUnit(args[0])
+		if err != nil {
+			return "", nil, err
+		}
+		if phase, o = Spring, OrderSupportHold(p, recipient); len(args) == 3 {
+			target, err := b.parseDAIDEProvince(args[2])
+			if err != nil {
+				return "", nil, err
+			}
+			o = OrderSupportMove(p, recipient, target, "")
+		}
+	case verb == "CVY" && len(args) == 3 && args[1].token == "CTO":
+		_, _, recipient, _, err := b.parseDAIDEUnit(args[0])
+		if err != nil {
+			return "", nil, err
+		}
+		target, err := b.parseDAIDEProvince(args[2])
+		if err != nil {
+			return "", nil, err
+		}
+		phase, o = Spring, OrderConvoy(p, recipient, target)
+	case verb == "DSB" && len(args) == 0:
+		phase, o = SpringRetreats, OrderHoldDisband(p)
+	case verb == "REM" && len(args) == 0:
+		o = OrderHoldDisband(p)
+	case verb == "BLD" && len(args) == 0:
+		o = OrderBuild(p, unit)
+		if unit == Fleet {
+			o.TargetCoast = coast
+		}
+	default:
+		return "", nil, fmt.Errorf("malformed order %s", strings.TrimSpace(text))
+	}
+	if phase.Move() != g.phase.Move() || phase.Retreat() != g.phase.Retreat() {
+		return "", nil, &daideSeasonError{verb, g.phase}
+	}
+	return country, &o, nil
+}
+
+// DAIDE writes the order in the tokens of the DAIDE protocol, as with
+// [StyleDAIDE], but with the country given for waives, like "( FRA WVE )".
+func (o Order) DAIDE(game *Game, country string) string {
+	return o.formatDAIDE(game, country)
+}
+
+// DAIDEMap writes the board as a DAIDE map definition, like
+// "MDF ( AUS ENG ... ) ( ( ( AUS BUD TRI VIE ) ... ( UNO BEL ... ) ) ( ADR ... ) )
+// ( ( ADR ( FLT ALB APU ION TRI VEN ) ) ... )": the countries, the home and
+// other supply centers, the other provinces, and where armies and fleets
+// in each province can move.
+//
+// Provinces are written as their DAIDE tokens (see [Board.DAIDEProvince]).
+func (b *Board) DAIDEMap() string {
+	var sb strings.Builder
+	sb.WriteString("MDF (")
+	for _, c := range b.countries {
+		sb.WriteString(" " + DAIDECountry(c))
+	}
+	sb.WriteString(" ) ( (")
+	for _, c := range append(slices.Clone(b.countries), "") {
+		var centers []string
+		for p := range b.Centers() {
+			if p.country == c {
+				centers = append(centers, b.DAIDEProvince(p))
+			}
+		}
+		if len(centers) == 0 {
+			continue
+		}
+		owner := "UNO"
+		if c != "" {
+			owner = DAIDECountry(c)
+		}
+		sb.WriteString(" ( " + owner + " " + strings.Join(centers, " ") + " )")
+	}
+	sb.WriteString(" ) (")
+	for _, p := range b.provinces {
+		if !p.center {
+			sb.WriteString(" " + b.DAIDEProvince(p))
+		}
+	}
+	sb.WriteString(" ) ) (")
+	for _, p := range b.provinces {
+		sb.WriteString(" ( " + b.DAIDEProvince(p))
+		var armies []string
+		fleets := make(map[string][]string)
+		for _, q := range b.provinces {
+			c := b.Connection(p, q)
+			if c == nil {
+				continue
+			}
+			if c.Traversable(Army) {
+				armies = append(armies, b.DAIDEProvince(q))
+			}
+			if !c.Traversable(Fleet) {
+				continue
+			}
+			from := c.fromCoasts
+			if len(from) == 0 {
+				from = []string{""}
+			}
+			for _, fc := range from {
+				if len(c.toCoasts) == 0 {
+					fleets[fc] = append(fleets[fc], b.DAIDEProvince(q))
+				}
+				for _, tc := range c.toCoasts {
+					fleets[fc] = append(fleets[fc], "( "+b.DAIDEProvince(q)+" "+daideCoast(tc)+" )")
+				}
+			}
+		}
+		if len(armies) > 0 {
+			sb.WriteString(" ( AMY " + strings.Join(armies, " ") + " )")
+		}
+		for _, fc := range append([]string{""}, p.coasts...) {
+			if len(fleets[fc]) == 0 {
+				continue
+			}
+			unit := "FLT"
+			if fc != "" {
+				unit = "( FLT " + daideCoast(fc) + " )"
+			}
+			sb.WriteString(" ( " + unit + " " + strings.Join(fleets[fc], " ") + " )")
+		}
+		sb.WriteString(" )")
+	}
+	sb.WriteString(" )")
+	return sb.String()
+}
+
+// daideAdjacency is what is known of the connection between two provinces
+// from a DAIDE map definition.
+type daideAdjacency struct {
+	army, fleet          bool
+	fromCoasts, toCoasts []string
+}
+
+// ParseDAIDEMap builds a board from a DAIDE map definition, as written by
+// [Board.DAIDEMap]. Case is ignored.
+//
+// Countries whose tokens are the first three letters of a standard country,
+// like "FRA", are given its name; others are named by their tokens. Since the
+// map gives no names for provinces, each is named and abbreviated by its
+// token. A province's terrain follows from which units can move from it:
+// armies only for inland provinces, fleets only for water, and both for
+// coastal provinces.
+func ParseDAIDEMap(mdf string) (*Board, error) {
+	exprs, err := daideMessage(mdf, "MDF")
+	if err != nil {
+		return nil, err
+	}
+	if len(exprs) != 3 || exprs[1].token != "" || len(exprs[1].list) != 2 {
+		return nil, errors.New("MDF needs countries, provinces, and adjacencies")
+	}
+	var bl Builder
+	countries := make(map[string]string)
+	custom := false
+	for _, e := range exprs[0].list {
+		if e.token == "" || e.token == "UNO" {
+			return nil, fmt.Errorf("bad country %s", e)
+		}
+		name, ok := DefaultCountryParser(e.token)
+		if !ok || DAIDECountry(name) != e.token {
+			name, custom = e.token, true
+		}
+		countries[e.token] = name
+		bl.Countries = append(bl.Countries, name)
+	}
+	if custom {
+		bl.CountryParser = func(s string) (string, bool) {
+			for _, c := range bl.Countries {
+				if strings.EqualFold(c, s) || strings.EqualFold(DAIDECountry(c), s) {
+					return c, true
+				}
+			}
+			return "", false
+		}
+	}
+	// Supply centers, with their home countries, and then other provinces.
+	homes := make(map[string]string)
+	centers := make(map[string]bool)
+	for _, e := range exprs[1].list[0].list {
+		if len(e.list) == 0 {
+			return nil, fmt.Errorf("bad supply centers %s", e)
+		}
+		owner := e.list[0]
+		if owner.token == "" {
+			return nil, fmt.Errorf("centers with several home countries are not supported: %s", e)
+		}
+		country, ok := countries[owner.token]
+		if !ok && owner.token != "UNO" {
+			return nil, &UnknownCountryError{owner.token}
+		}
+		for _, p := range e.list[1:] {
+			if p.token == "" || centers[p.token] {
+				return nil, fmt.Errorf("bad supply center %s", p)
+			}
+			centers[p.token] = true
+			homes[p.token] = country
+		}
+	}
+	known := maps.Clone(centers)
+	for _, p := range exprs[1].list[1].list {
+		if p.token == "" || known[p.token] {
+			return nil, fmt.Errorf("bad province %s", p)
+		}
+		known[p.token] = true
+	}
+	// Adjacencies, by the pair of provinces in order of appearance.
+	var order []string
+	index := make(map[string]int)
+	coasts := make(map[string][]string)
+	// Whether armies and fleets can move from each province.
+	terrains := make(map[string][2]bool)
+	coastal := func(token string) bool {
+		return terrains[token][0] && terrains[token][1]
+	}
+	adjacencies := make(map[[2]string]*daideAdjacency)
+	parseCoast := func(token string) (string, error) {
+		for _, c := range []string{"NC", "EC", "SC", "WC"} {
+			if daideCoast(c) == token {
+				return c, nil
+			}
+		}
+		return "", fmt.Errorf("unknown coast %s", token)
+	}
+	for _, e := range exprs[2].list {
+		if len(e.list) == 0 || !known[e.list[0].token] {
+			return nil, fmt.Errorf("bad adjacency %s", e)
+		}
+		from := e.list[0].token
+		if _, ok := index[from]; ok {
+			return nil, fmt.Errorf("adjacencies of %s given twice", from)
+		}
+		index[from] = len(order)
+		order = append(order, from)
+		for _, ue := range e.list[1:] {
+			if len(ue.list) == 0 {
+				return nil, fmt.Errorf("bad adjacency %s", ue)
+			}
+			unit, fromCoast := ue.list[0].token, ""
+			if u := ue.list[0]; len(u.list) == 2 && u.list[0].token == "FLT" {
+				if fromCoast, err = parseCoast(u.list[1].token); err != nil {
+					return nil, err
+				}
+				unit = "FLT"
+				coasts[from] = append(coasts[from], fromCoast)
+			}
+			t := terrains[from]
+			switch unit {
+			case "AMY":
+				t[0] = true
+			case "FLT":
+				t[1] = true
+			default:
+				return nil, fmt.Errorf("bad adjacency %s", ue)
+			}
+			terrains[from] = t
+			for _, te := range ue.list[1:] {
+				to, toCoast := te.token, ""
+				if len(te.list) == 2 {
+					to = te.list[0].token
+					if toCoast, err = parseCoast(te.list[1].token); err != nil {
+						return nil, err
+					}
+				}
+				if !known[to] || to == from {
+					return nil, fmt.Errorf("bad adjacency %s - %s", from, te)
+				}
+				// Adjacencies are listed from both ends; keep one per pair.
+				key, fc, tc := [2]string{from, to}, fromCoast, toCoast
+				if to < from {
+					key, fc, tc = [2]string{to, from}, toCoast, fromCoast
+				}
+				a := adjacencies[key]
+				if a == nil {
+					a = &daideAdjacency{}
+					adjacencies[key] = a
+				}
+				if unit == "AMY" {
+					a.army = true
+					continue
+				}
+				a.fleet = true
+				if fc != "" && !slices.Contains(a.fromCoasts, fc) {
+					a.fromCoasts = append(a.fromCoasts, fc)
+				}
+				if tc != "" && !slices.Contains(a.toCoasts, tc) {
+					a.toCoasts = append(a.toCoasts, tc)
+				}
+			}
+		}
+	}
+	for _, token := range order {
+		p := BuilderProvince{
+			Name:          token,
+			Abbreviations: []string{token},
+			Center:        centers[token],
+			Country:       homes[token],
+		}
+		switch t := terrains[token]; {
+		case coastal(token):
+			p.Terrain = Coastal
+			p.Coasts = coasts[token]
+		case t[0]:
+			p.Terrain = Inland
+		case t[1]:
+			p.Terrain = Water
+		default:
+			return nil, fmt.Errorf("province %s has no adjacencies", token)
+		}
+		bl.Provinces = append(bl.Provinces, p)
+	}
+	for token := range known {
+		if _, ok := index[token]; !ok {
+			return nil, fmt.Errorf("province %s has no adjacencies", token)
+		}
+	}
+	keys := slices.Collect(maps.Keys(adjacencies))
+	slices.SortFunc(keys, func(a, b [2]string) int {
+		if d := index[a[0]] - index[b[0]]; d != 0 {
+			return d
+		}
+		return index[a[1]] - index[b[1]]
+	})
+	for _, key := range keys {
+		a := adjacencies[key]
+		c := BuilderConnection{From: key[0], To: key[1]}
+		if a.fleet {
+			c.FromCoasts, c.ToCoasts = a.fromCoasts, a.toCoasts
+			c.Coastal = coastal(key[0]) && coastal(key[1])
+		}
+		bl.Connections = append(bl.Connections, c)
+	}
+	return bl.Build()
+}
+
+// DAIDEPosition writes the game state as DAIDE messages: now gives the
+// season, year, and units, like "NOW ( SPR 1901 ) ( AUS AMY BUD ) ...", with
+// the provinces each dislodged unit may retreat to after MRT, and sco gives
+// who controls each supply center, like "SCO ( AUS BUD TRI VIE ) ...
+// ( UNO BEL ... )".
+func (g *Game) DAIDEPosition() (now, sco string) {
+	b := g.board
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "NOW ( %s %d )", daideSeasons[g.phase], g.year)
+	for _, p := range b.provinces {
+		if u := g.units[p]; u != nil {
+			sb.WriteString(" " + daideUnitAt(b, u.country, u.unit, p, u.coast))
+		}
+	}
+	for _, p := range b.provinces {
+		u := g.dislodged[p]
+		if u == nil {
+			continue
+		}
+		sb.WriteString(" " + g.daideDislodged(u))
+	}
+	now = sb.String()
+	sb.Reset()
+	sb.WriteString("SCO")
+	for _, c := range append(slices.Clone(b.countries), "") {
+		var centers []string
+		for p := range b.Centers() {
+			if g.centers[p] == c {
+				centers = append(centers, b.DAIDEProvince(p))
+			}
+		}
+		if len(centers) == 0 {
+			continue
+		}
+		owner := "UNO"
+		if c != "" {
+			owner = DAIDECountry(c)
+		}
+		sb.WriteString(" ( " + owner + " " + strings.Join(centers, " ") + " )")
+	}
+	return now, sb.String()
+}
+
+// daideDislodged writes a dislodged unit with the provinces it may retreat
+// to, like "( GER AMY MUN MRT ( BOH SIL ) )".
+func (g *Game) daideDislodged(u *Occupancy) string {
+	b := g.board
+	var retreats []string
+	for q := range g.Neighbors(u) {
+		if g.contests[q] || g.units[q] != nil || q == g.attackers[u] {
+			continue
+		}
+		c := b.Connection(u.province, q)
+		if u.unit != Fleet || len(c.toCoasts) == 0 {
+			retreats = append(retreats, b.DAIDEProvince(q))
+			continue
+		}
+		for _, tc := range c.toCoasts {
+			retreats = append(retreats, "( "+b.DAIDEProvince(q)+" "+daideCoast(tc)+" )")
+		}
+	}
+	slices.Sort(retreats)
+	unit := daideUnitAt(b, u.country, u.unit, u.province, u.coast)
+	return fmt.Sprintf("%s MRT ( %s ) )", unit[:len(unit)-2], strings.Join(retreats, " "))
+}
+
+// ParseDAIDEPosition reads a game state on the board from DAIDE NOW and SCO
+// messages, as written by [Game.DAIDEPosition]. If sco is empty, each
+// country controls its home supply centers, as with [NewGame]. Case is
+// ignored.
+//
+// DAIDE gives only where each dislodged unit may retreat. A province a unit
+// may not retreat to, though it could move there and the province is empty,
+// is taken to be where its attacker came from if another dislodged unit may
+// retreat there, and otherwise to be contested.
+func ParseDAIDEPosition(board *Board, now, sco string) (*Game, error) {
+	if board == nil {
+		return nil, errors.New("no board given")
+	}
+	exprs, err := daideMessage(now, "NOW")
+	if err != nil {
+		return nil, err
+	}
+	if len(exprs) == 0 || len(exprs[0].list) != 2 {
+		return nil, errors.New("NOW needs a season and year")
+	}
+	g := NewGame(board)
+	phase := slices.Index(daideSeasons[:], exprs[0].list[0].token)
+	if phase < 0 {
+		return nil, fmt.Errorf("unknown season %s", exprs[0].list[0])
+	}
+	g.phase = Phase(phase)
+	if g.year, err = strconv.Atoi(exprs[0].list[1].token); err != nil {
+		return nil, fmt.Errorf("bad year %s", exprs[0].list[1])
+	}
+	if g.year < StartYear {
+		return nil, fmt.Errorf("year %d is before %d", g.year, StartYear)
+	}
+	retreats := make(map[*Occupancy][]*Province)
+	for _, e := range exprs[1:] {
+		var mrt *daideExpr
+		if len(e.list) == 5 && e.list[3].token == "MRT" {
+			mrt = &e.list[4]
+			e = daideExpr{list: e.list[:3]}
+		}
+		country, unit, p, coast, err := board.parseDAIDEUnit(e)
+		if err != nil {
+			return nil, err
+		}
+		u, err := g.validSetUnit(p, coast, unit, country)
+		if err != nil {
+			return nil, fmt.Errorf("unit in %s: %w", p.name, err)
+		}
+		if mrt == nil {
+			if g.units[p] != nil {
+				return nil, fmt.Errorf("two units in %s", p.name)
+			}
+			g.units[p] = u
+			continue
+		}
+		if !g.phase.Retreat() {
+			return nil, errors.New("dislodged units are only allowed in retreat phases")
+		}
+		if g.dislodged[p] != nil {
+			return nil, fmt.Errorf("two dislodged units in %s", p.name)
+		}
+		g.dislodged[p] = u
+		for _, re := range mrt.list {
+			q, _, err := board.parseDAIDELocation(re)
+			if err != nil {
+				return nil, err
+			}
+			retreats[u] = append(retreats[u], q)
+		}
+	}
+	allowed := make(map[*Province]bool)
+	for _, ps := range retreats {
+		for _, q := range ps {
+			allowed[q] = true
+		}
+	}
+	for u, ps := range retreats {
+		for q := range g.Neighbors(u) {
+			if g.units[q] != nil || slices.Contains(ps, q) {
+				continue
+			}
+			if allowed[q] && g.attackers[u] == nil {
+				g.attackers[u] = q
+			} else if !allowed[q] {
+				g.contests[q] = true
+			}
+		}
+	}
+	if sco == "" {
+		return g, nil
+	}
+	if exprs, err = daideMessage(sco, "SCO"); err != nil {
+		return nil, err
+	}
+	for p := range g.centers {
+		g.centers[p] = ""
+	}
+	for _, e := range exprs {
+		if len(e.list) == 0 {
+			return nil, fmt.Errorf("bad supply centers %s", e)
+		}
+		country := ""
+		if e.list[0].token != "UNO" {
+			if country, err = board.parseDAIDECountry(e.list[0]); err != nil {
+				return nil, err
+			}
+		}
+		for _, pe := range e.list[1:] {
+			p, err := board.parseDAIDEProvince(pe)
+			if err != nil {
+				return nil, err
+			}
+			if err := board.validCenter(p); err != nil {
+				return nil, err
+			}
+			g.centers[p] = country
+		}
+	}
+	return g, nil
+}
+
+// daideNote is the note the DAIDE protocol gives to an order that cannot be
+// accepted, for the outcome it has when added to an arena.
+func daideNote(outcome Outcome, phase Phase) string {
+	switch outcome {
+	case OutcomeMalformed:
+		return "NRS"
+	case OutcomeEnemyUnit:
+		return "NYU"
+	case OutcomeMissingUnit, OutcomeMissingRecipient:
+		if phase.Retreat() {
+			return "NRN"
+		}
+		return "NSU"
+	case OutcomeCoastAmbiguous:
+		return "CST"
+	case OutcomeBadConvoy:
+		return "NAS"
+	case OutcomeContested, OutcomeBadRetreatToAttacker:
+		return "NVR"
+	case OutcomeNoBuilds:
+		return "NMB"
+	case OutcomeNoDisbands:
+		return "NMR"
+	case OutcomeNotHome:
+		return "HSC"
+	case OutcomeNotControlled:
+		return "YSC"
+	case OutcomeOccupied, OutcomeRepeatUnit:
+		if phase.Retreat() {
+			return "NVR"
+		}
+		return "ESC"
+	default:
+		return "FAR"
+	}
+}
+
+// accepted tells whether an order added to the arena is legal, as opposed
+// to being stored with an outcome saying why it cannot work.
+func (a *Arena) accepted(order Order, outcome Outcome) bool {
+	switch {
+	case a.game.phase.Move():
+		uo, ok := a.unitOrders[a.game.Unit(order.Unit)]
+		return ok && uo.order == order && !uo.illegal
+	case a.game.phase.Retreat():
+		return outcome == OutcomeSuccess || outcome == OutcomeStandoff
+	default:
+		return outcome == OutcomeSuccess
+	}
+}
+
+// DAIDESubmit adds an order written in DAIDE tokens (see [Game.ParseDAIDEOrder])
+// for a country, as a DAIDE server does for each order of a SUB message, and
+// gives the reply, like "THX ( ( ( ENG FLT LON ) MTO NTH ) ) ( MBV )".
+//
+// An order replaces any order given before to the same unit, or to build in
+// the same province. Orders that cannot be carried out, like moves to
+// provinces the unit cannot reach, are not added; the reply gives a note
+// saying why instead of MBV. Orders that do not parse give an error.
+func (a *Arena) DAIDESubmit(country, text string) (string, error) {
+	exprs, err := parseDAIDE(text)
+	if err != nil {
+		return "", err
+	}
+	echo := daideExpr{list: exprs}
+	if len(exprs) == 1 && exprs[0].token == "" {
+		echo = exprs[0]
+	}
+	reply := func(note string) (string, error) {
+		return "THX " + echo.String() + " ( " + note + " )", nil
+	}
+	owner, order, err := a.game.ParseDAIDEOrder(text)
+	var seasonErr *daideSeasonError
+	var provinceErr *UnknownProvinceError
+	switch {
+	case errors.As(err, &seasonErr):
+		return reply("NRS")
+	case errors.As(err, &provinceErr):
+		return reply("NSP")
+	case err != nil:
+		return "", err
+	case owner != country:
+		return reply("NYU")
+	}
+	var replaced []Order
+	if p := order.province(); p != nil {
+		for _, o := range a.Orders(country) {
+			if o.province() == p && o != *order {
+				replaced = append(replaced, o)
+				a.Remove(country, o)
+			}
+		}
+	}
+	outcome, err := a.Add(country, *order)
+	if err != nil {
+		return "", err
+	}
+	if a.accepted(*order, outcome) {
+		return reply("MBV")
+	}
+	a.Remove(country, *order)
+	for _, o := range replaced {
+		a.Add(country, o)
+	}
+	return reply(daideNote(outcome, a.game.phase))
+}
+
+// DAIDEMissing gives the DAIDE MIS message saying which of a country's
+// orders are still missing: the units without orders in move phases, like
+// "MIS ( AUS AMY BUD ) ( AUS FLT TRI )", the dislodged units without orders,
+// with where they may retreat, in retreat phases, and in [Winter], the number
+// of units still to be removed, or negative for builds still to be ordered,
+// like "MIS ( -2 )". It is "MIS" alone when no orders are missing.
+func (a *Arena) DAIDEMissing(country string) string {
+	g := a.game
+	var missing []string
+	switch {
+	case g.phase.Move():
+		for _, p := range g.board.provinces {
+			if u := g.units[p]; u != nil && u.country == country && a.unitOrders[u] == nil {
+				missing = append(missing, daideUnitAt(g.board, country, u.unit, p, u.coast))
+			}
+		}
+	case g.phase.Retreat():
+		for _, p := range g.board.provinces {
+			if u := g.dislodged[p]; u != nil && u.country == country && a.unitOrders[u] == nil {
+				missing = append(missing, g.daideDislodged(u))
+			}
+		}
+	default:
+		n := a.buildCount[country]
+		if n > 0 {
+			open := 0
+			for p := range g.OpenHomeCenters(country) {
+				if _, ok := a.builds[p]; !ok {
+					open++
+				}
+			}
+			if n = min(n, open); a.waived[country] {
+				n = 0
+			}
+		}
+		if n != 0 {
+			missing = append(missing, fmt.Sprintf("( %d )", -n))
+		}
+	}
+	return strings.Join(append([]string{"MIS"}, missing...), " ")
+}
+
+// DAIDEResults gives the DAIDE ORD messages with the result of each order
+// added to the arena, like "ORD ( SPR 1901 ) ( ( ENG FLT LON ) MTO NTH )
+// ( SUC )", in board order. Call it after [Arena.Go], so that the default
+// orders given to units without orders are included.
+//
+// Results are SUC for orders that work, BNC for moves and retreats that
+// fail, CUT for cut supports, DSR for armies whose convoy was disrupted, NSO
+// for supports and convoys that cannot be given or match no order, and FLD
+// for builds and removals that fail. RET follows the result of a unit that
+// was dislodged.
+func (a *Arena) DAIDEResults() []string {
+	g := a.game
+	turn := fmt.Sprintf("( %s %d )", daideSeasons[g.phase], g.year)
+	var results []string
+	for _, c := range g.board.countries {
+		orders := a.Orders(c)
+		slices.SortFunc(orders, func(x, y Order) int {
+			return slices.Index(g.board.provinces, x.province()) -
+				slices.Index(g.board.provinces, y.province())
+		})
+		for _, o := range orders {
+			outcome := a.countryOrders[c][o]
+			if !outcomeAssigned(outcome) {
+				continue
+			}
+			result := "SUC"
+			switch {
+			case g.phase.Move():
+				u := g.Unit(o.Unit)
+				uo := a.unitOrders[u]
+				if uo == nil || uo.order != o {
+					continue
+				}
+				switch {
+				case uo.illegal, outcome == OutcomeBadRecipient, outcome == OutcomeMissingRecipient:
+					// Supports and convoys that match no order are void.
+					result = "NSO"
+				case outcome == OutcomeSuccess, outcome == OutcomeDislodged && o.Kind() == HoldDisband:
+				case o.Kind() == SupportHold || o.Kind() == SupportMove:
+					result = "CUT"
+				case outcome == OutcomeNoConvoy:
+					result = "DSR"
+				default:
+					result = "BNC"
+				}
+				if _, ok := a.attackers[u]; ok {
+					result += " RET"
+				}
+			case g.phase.Retreat():
+				if outcome != OutcomeSuccess {
+					result = "BNC"
+				}
+			default:
+				if outcome != OutcomeSuccess {
+					result = "FLD"
+				}
+			}
+			results = append(results, fmt.Sprintf("ORD %s %s ( %s )", turn, o.DAIDE(g, c), result))
+		}
+	}
+	return results
+}
@@ -0,0 +1,62 @@
+package diplo
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDAIDEResultsRetreat(t *testing.T) {
+	// England's fleet was dislodged from Bulgaria by the Turkish army now there.
+	g, err := ParseNotation(StandardBoard, "F1901R E:FNTH;T:ABul,FBla;D:FBul/EC=E<Con")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := g.Arena()
+	if _, err := a.Add("England", mustParseOrder(t, g, "England", "F Bul - Rum")); err != nil {
+		t.Fatal(err)
+	}
+	a.Go()
+	want := []string{"ORD ( AUT 1901 ) ( ( ENG FLT ( BUL ECS ) ) RTO RUM ) ( SUC )"}
+	got := a.DAIDEResults()
+	if !slices.Equal(got, want) {
+		t.Fatalf("DAIDEResults() = %q, want %q", got, want)
+	}
+	order := strings.TrimSuffix(strings.TrimPrefix(got[0], "ORD ( AUT 1901 ) "), " ( SUC )")
+	country, o, err := g.ParseDAIDEOrder(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if country != "England" || o.Unit != StandardBoard.ParseProvince("Bul")[0] {
+		t.Errorf("ParseDAIDEOrder(%q) = %s %s", order, country, o.Format(g, StyleWebDip))
+	}
+}
+
+func TestDAIDEResultsSupports(t *testing.T) {
+	g, err := ParseNotation(StandardBoard, "S1901M G:ABer,AMun,ASil;R:AWar;SC:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := g.Arena()
+	for _, o := range []struct{ country, text string }{
+		{"Germany", "A Ber - Pru"},
+		{"Germany", "A Mun S A Ber - Sil"},
+		{"Germany", "A Sil S A Ber - Pru"},
+		{"Russia", "A War - Sil"},
+	} {
+		if _, err := a.Add(o.country, mustParseOrder(t, g, o.country, o.text)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.Go()
+	want := []string{
+		"ORD ( SPR 1901 ) ( ( GER AMY BER ) MTO PRU ) ( SUC )",
+		// Berlin does not move to Silesia, so the support matches no order.
+		"ORD ( SPR 1901 ) ( ( GER AMY MUN ) SUP ( GER AMY BER ) MTO SIL ) ( NSO )",
+		"ORD ( SPR 1901 ) ( ( GER AMY SIL ) SUP ( GER AMY BER ) MTO PRU ) ( CUT )",
+		"ORD ( SPR 1901 ) ( ( RUS AMY WAR ) MTO SIL ) ( BNC )",
+	}
+	if got := a.DAIDEResults(); !slices.Equal(got, want) {
+		t.Errorf("DAIDEResults() =\n%q\nwant\n%q", got, want)
+	}
+}
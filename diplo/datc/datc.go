@@ -0,0 +1,440 @@
+// Package datc checks the adjudicator against the Diplomacy Adjudicator Test Cases
+// (DATC) by Lucas Kruijswijk, played out on [diplo.StandardBoard].
+//
+// Cases are written in a small line-based language, so that new cases (for example,
+// from bug reports) can be added without writing Go:
+//
+//	# Comments start with a hash.
+//	case 6.D.2 A move cuts support on hold
+//	Austria:
+//	  F ADR S A Tri - Ven
+//	  A Tri - Ven => Success
+//	  A Vie - Tyr
+//	Italy:
+//	  A Ven H => Dislodged
+//	  A Tyr S A Ven => Cut
+//	go
+//	dislodged Ven
+//
+// A case begins with "case", an identifier, and a title. Identifiers must be
+// unique; a case checked under a rule other than the preferred one adds the rule
+// after a slash, like "6.F.16/1982". The lines that follow are:
+//
+//   - "phase" and a season and year, like "phase Fall 1901", to start the case
+//     in a phase other than Spring 1901. Retreat phases are not supported.
+//   - "paradox" and the name of a [diplo.ParadoxRule], like "paradox 1982", to
+//     decide convoy paradoxes by a rule other than the preferred one.
+//   - "center", a country, and a list of supply centers, like "center Russia Ber",
+//     to give the country control of them before any go.
+//   - A country name followed by a colon, which gives the country for the order
+//     lines below it.
+//   - Order lines, in the format accepted by [diplo.Game.ParseOrder], each starting
+//     with the unit type. Before the first "go", the unit is placed on the board
+//     (with a coast after a slash, like "F Spa/sc") by the first line that names its
+//     province; a line with only a unit places it without giving an order. Build,
+//     waive, and disband orders starting with a word, like "Build A Kie", place no
+//     unit. An order may be followed by "=>" and the name of its expected
+//     [diplo.Outcome].
+//   - "go", which checks the expected outcomes and adjudicates the phase.
+//   - "dislodged" and a list of provinces, which must be exactly the units
+//     dislodged in the current (retreat) phase.
+//   - "unit", a country, and a unit, like "unit France F Spa/nc", which must
+//     be on the board.
+//   - "empty" and a list of provinces, which must not be occupied.
+package datc
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	diplo "github.com/adambyle/diplopad"
+)
+
+//go:embed cases.txt
+var corpus string
+
+// Case is a single test case: a position, orders, and expected results.
+type Case struct {
+	// ID is the case identifier, like "6.A.11".
+	ID string
+	// Title is the description of the case.
+	Title string
+	// Line is where the case starts in its source.
+	Line  int
+	lines []line
+}
+
+type line struct {
+	n    int
+	text string
+}
+
+// Parse reads cases written in the test case language.
+func Parse(r io.Reader) ([]*Case, error) {
+	var (
+		cases []*Case
+		c     *Case
+		n     = 0
+		ids   = make(map[string]int)
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		n += 1
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(text, "case "); ok {
+			id, title, _ := strings.Cut(strings.TrimSpace(rest), " ")
+			if first, ok := ids[id]; ok {
+				return nil, fmt.Errorf("line %d: case %s repeats line %d", n, id, first)
+			}
+			ids[id] = n
+			c = &Case{ID: id, Title: strings.TrimSpace(title), Line: n}
+			cases = append(cases, c)
+			continue
+		}
+		if c == nil {
+			return nil, fmt.Errorf("line %d: expected case", n)
+		}
+		c.lines = append(c.lines, line{n, text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+// Cases is the bundled corpus of DATC cases.
+func Cases() []*Case {
+	cases, err := Parse(strings.NewReader(corpus))
+	if err != nil {
+		panic(err)
+	}
+	return cases
+}
+
+// Find gets a bundled case by its identifier, or nil if there is none.
+// Cases under other rules have their own identifiers (see the package
+// documentation), so Find("6.F.16") gets only the case under the preferred rule.
+func Find(id string) *Case {
+	for _, c := range Cases() {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+type expectation struct {
+	n       int
+	country string
+	order   diplo.Order
+	outcome diplo.Outcome
+}
+
+type runner struct {
+	board   *diplo.Board
+	game    *diplo.Game
+	errs    []string
+	started bool
+}
+
+func (r *runner) fail(n int, format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf("line %d: ", n)+fmt.Sprintf(format, args...))
+}
+
+// Run plays out the case, returning an error describing every
+// result that differs from what was expected.
+func (c *Case) Run() error {
+	r := &runner{
+		board: diplo.StandardBoard,
+		game:  diplo.NewGame(diplo.StandardBoard),
+	}
+	var phase []line
+	for _, l := range c.lines {
+		fields := strings.Fields(l.text)
+		switch fields[0] {
+		case "phase":
+			r.setPhase(l, fields[1:])
+		case "paradox":
+			r.setParadoxRule(l, fields[1:])
+		case "center":
+			r.setCenters(l, fields[1:])
+		case "go":
+			r.play(phase)
+			phase = nil
+		case "dislodged":
+			r.checkDislodged(l, fields[1:])
+		case "unit":
+			r.checkUnit(l, fields[1:])
+		case "empty":
+			r.checkEmpty(l, fields[1:])
+		default:
+			phase = append(phase, l)
+		}
+	}
+	if len(phase) > 0 {
+		r.fail(phase[0].n, "orders not followed by go")
+	}
+	if len(r.errs) > 0 {
+		return fmt.Errorf("case %s (%s):\n%s", c.ID, c.Title, strings.Join(r.errs, "\n"))
+	}
+	return nil
+}
+
+func (r *runner) setPhase(l line, fields []string) {
+	if r.started || len(fields) != 2 {
+		r.fail(l.n, "phase must be a season and year before any go")
+		return
+	}
+	var phase diplo.Phase
+	switch strings.ToLower(fields[0]) {
+	case "spring":
+		phase = diplo.Spring
+	case "fall", "autumn":
+		phase = diplo.Fall
+	case "winter":
+		phase = diplo.Winter
+	default:
+		r.fail(l.n, "unknown season %s", fields[0])
+		return
+	}
+	var year int
+	if _, err := fmt.Sscan(fields[1], &year); err != nil {
+		r.fail(l.n, "bad year %s", fields[1])
+		return
+	}
+	r.game.SetPhase(phase)
+	r.game.SetYear(year)
+}
+
+func (r *runner) setParadoxRule(l line, fields []string) {
+	if len(fields) != 1 {
+		r.fail(l.n, "expected paradox rule")
+		return
+	}
+	rule, ok := diplo.ParseParadoxRule(fields[0])
+	if !ok {
+		r.fail(l.n, "unknown paradox rule %s", fields[0])
+		return
+	}
+	rules := r.game.Rules()
+	rules.Paradox = rule
+	r.game.SetRules(rules)
+}
+
+func (r *runner) setCenters(l line, fields []string) {
+	if r.started || len(fields) < 2 {
+		r.fail(l.n, "center must be a country and supply centers before any go")
+		return
+	}
+	country, ok := r.board.ParseCountry(fields[0])
+	if !ok {
+		r.fail(l.n, "unknown country %s", fields[0])
+		return
+	}
+	for _, p := range r.provinces(l, fields[1:]) {
+		if err := r.game.TakeCenter(p, country); err != nil {
+			r.fail(l.n, "%v", err)
+		}
+	}
+}
+
+// unit interprets a unit such as "F Spa/nc".
+func (r *runner) unit(kind, name string) (diplo.Unit, *diplo.Province, string, error) {
+	var unit diplo.Unit
+	switch strings.ToUpper(kind) {
+	case "A":
+		unit = diplo.Army
+	case "F":
+		unit = diplo.Fleet
+	default:
+		return 0, nil, "", fmt.Errorf("unknown unit type %s", kind)
+	}
+	name, coast, _ := strings.Cut(name, "/")
+	ps := r.board.ParseProvince(name)
+	if len(ps) != 1 {
+		return 0, nil, "", fmt.Errorf("unknown province %s", name)
+	}
+	if coast != "" {
+		var ok bool
+		if coast, ok = r.board.ParseCoast(coast); !ok {
+			return 0, nil, "", fmt.Errorf("unknown coast %s", coast)
+		}
+	}
+	return unit, ps[0], coast, nil
+}
+
+func (r *runner) country(l line) (string, bool) {
+	name, ok := strings.CutSuffix(l.text, ":")
+	if !ok {
+		return "", false
+	}
+	country, ok := r.board.ParseCountry(name)
+	if !ok {
+		r.fail(l.n, "unknown country %s", name)
+	}
+	return country, true
+}
+
+// play adds the orders of a phase to an arena, checks their outcomes,
+// and moves on to the next phase.
+func (r *runner) play(lines []line) {
+	// Units are placed before any orders are given.
+	if !r.started {
+		country := ""
+		for _, l := range lines {
+			if c, ok := r.country(l); ok {
+				country = c
+				continue
+			}
+			fields := strings.Fields(l.text)
+			if len(fields) < 2 {
+				r.fail(l.n, "expected unit")
+				continue
+			}
+			switch strings.ToLower(fields[0]) {
+			case "build", "b", "waive", "disband", "remove":
+				continue
+			}
+			unit, p, coast, err := r.unit(fields[0], fields[1])
+			if err != nil {
+				r.fail(l.n, "%v", err)
+				continue
+			}
+			if r.game.Unit(p) != nil {
+				// Ordering another country's unit.
+				continue
+			}
+			if err := r.game.SetUnit(p, coast, unit, country); err != nil {
+				r.fail(l.n, "%v", err)
+			}
+		}
+		r.started = true
+	}
+	arena := r.game.Arena()
+	var (
+		country  string
+		expected []expectation
+	)
+	for _, l := range lines {
+		if c, ok := r.country(l); ok {
+			country = c
+			continue
+		}
+		text, result, hasResult := strings.Cut(l.text, "=>")
+		if len(strings.Fields(text)) < 3 && !hasResult {
+			// Unit placement only.
+			continue
+		}
+		order, err := r.game.ParseOrder(text, country)
+		if err != nil {
+			r.fail(l.n, "%v", err)
+			continue
+		}
+		if _, err := arena.Add(country, *order); err != nil {
+			r.fail(l.n, "%v", err)
+			continue
+		}
+		if hasResult {
+			outcome, ok := diplo.ParseOutcome(strings.TrimSpace(result))
+			if !ok {
+				r.fail(l.n, "unknown outcome %s", strings.TrimSpace(result))
+				continue
+			}
+			expected = append(expected, expectation{l.n, country, *order, outcome})
+		}
+	}
+	for _, e := range expected {
+		if got := arena.Query(e.country, e.order); got != e.outcome {
+			r.fail(e.n, "expected %v, got %v", e.outcome, got)
+		}
+	}
+	r.game = arena.Go()
+}
+
+func (r *runner) provinces(l line, names []string) []*diplo.Province {
+	var ps []*diplo.Province
+	for _, name := range names {
+		found := r.board.ParseProvince(name)
+		if len(found) != 1 {
+			r.fail(l.n, "unknown province %s", name)
+			continue
+		}
+		ps = append(ps, found[0])
+	}
+	return ps
+}
+
+func (r *runner) checkDislodged(l line, names []string) {
+	want := r.provinces(l, names)
+	var got []*diplo.Province
+	for u := range r.game.AllDislodged() {
+		got = append(got, u.Province())
+	}
+	for _, p := range want {
+		if !slices.Contains(got, p) {
+			r.fail(l.n, "%s not dislodged", p.Name())
+		}
+	}
+	for _, p := range got {
+		if !slices.Contains(want, p) {
+			r.fail(l.n, "%s dislodged", p.Name())
+		}
+	}
+}
+
+func (r *runner) checkUnit(l line, fields []string) {
+	if len(fields) != 3 {
+		r.fail(l.n, "expected country and unit")
+		return
+	}
+	country, ok := r.board.ParseCountry(fields[0])
+	if !ok {
+		r.fail(l.n, "unknown country %s", fields[0])
+		return
+	}
+	unit, p, coast, err := r.unit(fields[1], fields[2])
+	if err != nil {
+		r.fail(l.n, "%v", err)
+		return
+	}
+	o := r.game.Unit(p)
+	if o == nil {
+		r.fail(l.n, "no unit in %s", p.Name())
+		return
+	}
+	c, _ := o.Coast()
+	if o.Country() != country || o.Unit() != unit || !strings.EqualFold(c, coast) {
+		r.fail(l.n, "wrong unit in %s", p.Name())
+	}
+}
+
+func (r *runner) checkEmpty(l line, names []string) {
+	for _, p := range r.provinces(l, names) {
+		if r.game.Unit(p) != nil {
+			r.fail(l.n, "%s occupied", p.Name())
+		}
+	}
+}
+
+// RunAll runs every case, joining the errors of those that fail.
+func RunAll(cases []*Case) error {
+	var errs []error
+	for _, c := range cases {
+		if err := c.Run(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,35 @@
+package datc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunAll(t *testing.T) {
+	if err := RunAll(Cases()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	for _, id := range []string{"6.A.1", "6.F.16", "6.F.16/1982", "6.F.19/DPTG"} {
+		c := Find(id)
+		if c == nil {
+			t.Errorf("Find(%q) = nil", id)
+			continue
+		}
+		if c.ID != id {
+			t.Errorf("Find(%q).ID = %q", id, c.ID)
+		}
+	}
+	if c := Find("6.Z.1"); c != nil {
+		t.Errorf("Find(6.Z.1) = %s, want nil", c.ID)
+	}
+}
+
+func TestParseRepeatedID(t *testing.T) {
+	_, err := Parse(strings.NewReader("case 1 First\ngo\ncase 1 Second\ngo\n"))
+	if err == nil {
+		t.Fatal("Parse accepted a repeated case identifier")
+	}
+}
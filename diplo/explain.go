@@ -0,0 +1,355 @@
+package diplo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ForceKind is which strength of an order is being measured.
+type ForceKind int
+
+const (
+	// ForceHold is the strength with which a unit keeps its province.
+	ForceHold ForceKind = iota
+	// ForceAttack is the strength with which a unit moves into its target.
+	ForceAttack
+	// ForceDefend is the strength with which a unit holds off a unit
+	// moving the opposite way, in a head-to-head battle.
+	ForceDefend
+	// ForcePrevent is the strength with which a unit keeps others from
+	// moving into its target.
+	ForcePrevent
+)
+
+var forceKindNames = [...]string{
+	ForceHold:    "hold",
+	ForceAttack:  "attack",
+	ForceDefend:  "defend",
+	ForcePrevent: "prevent",
+}
+
+// String is the lowercase name of the strength, e.g. "prevent".
+func (k ForceKind) String() string {
+	if k < 0 || int(k) >= len(forceKindNames) {
+		return fmt.Sprintf("ForceKind(%d)", int(k))
+	}
+	return forceKindNames[k]
+}
+
+// Force is the strength of a unit's order in a contest over a province.
+type Force struct {
+	// Unit is the unit whose order has the strength.
+	Unit *Occupancy
+	// Order is the unit's order.
+	Order Order
+	// Kind is which strength is measured.
+	Kind ForceKind
+	// Strength counts the unit itself and its supports.
+	Strength int
+	// Supporters are the units whose supports counted.
+	Supporters []*Occupancy
+}
+
+// Explanation is a trace of why an order has its outcome. See [Arena.Explain].
+type Explanation struct {
+	game *Game
+	// Country is who gave the order.
+	Country string
+	// Order is the order explained.
+	Order Order
+	// Outcome is the result of the order.
+	Outcome Outcome
+	// Rule describes the rule that decided the outcome.
+	Rule string
+	// Force is the strength of the order: the attack of a move, or the hold
+	// of any other order. It is nil if the order was not adjudicated.
+	Force *Force
+	// Opposition is the strength of the units contesting the order. For a move,
+	// these are the unit in its target and the other units moving there; for other
+	// orders, the units moving into the unit's province.
+	Opposition []*Force
+	// CutBy is the units whose attacks cut a support.
+	CutBy []*Occupancy
+	// DislodgedBy is the unit that dislodged the ordered unit, if any.
+	DislodgedBy *Occupancy
+	// Convoyed tells whether an Army's move goes by convoy.
+	Convoyed bool
+	// Convoy is the provinces of the fleets that carried a convoyed Army, in order.
+	// It is nil if no chain of convoys succeeded.
+	Convoy []*Province
+	// Details explains the orders the outcome depended on that failed: supports
+	// of the order that did not count, and the convoys of a convoyed Army.
+	Details []*Explanation
+}
+
+var outcomeRules = [...]string{
+	OutcomeSuccess:              "The order succeeds.",
+	OutcomeMalformed:            "The order does not take a valid form for the phase.",
+	OutcomeRepeatUnit:           "Each unit or province may only be given one order.",
+	OutcomeEnemyUnit:            "Countries may only order their own units.",
+	OutcomeMissingUnit:          "There is no unit to order.",
+	OutcomeBadTerrain:           "Armies cannot enter water, and fleets cannot enter inland provinces.",
+	OutcomeBadTarget:            "The unit cannot reach its target.",
+	OutcomeBadCoast:             "The fleet cannot reach the coast given.",
+	OutcomeCoastAmbiguous:       "The fleet could reach more than one coast, and none was given.",
+	OutcomeNoConvoy:             "An army moving by convoy needs an unbroken chain of convoying fleets.",
+	OutcomeBadRecipient:         "A support or convoy must match the order of the unit it aids.",
+	OutcomeMissingRecipient:     "There is no unit to support or convoy.",
+	OutcomeDislodged:            "A unit is dislodged when another unit moves into its province, and its order fails.",
+	OutcomeCut:                  "A support is cut by an attack from another country, unless the attack comes from where the support is directed.",
+	OutcomeWeak:                 "A move fails when its attack is not stronger than the unit in its target.",
+	OutcomeStandoff:             "A move fails when another unit moving to the same province is at least as strong.",
+	OutcomeOverpowered:          "A move fails when a stronger unit moves into the same province.",
+	OutcomeContested:            "Units cannot retreat to a province left vacant by a standoff.",
+	OutcomeBadRetreatToAttacker: "Units cannot retreat to the province their attacker came from.",
+	OutcomeNoBuilds:             "Countries may build only as many units as they have supply centers beyond their units.",
+	OutcomeNoDisbands:           "Countries may disband only as many units as they have beyond their supply centers.",
+	OutcomeNotHome:              "Units may only be built on home supply centers.",
+	OutcomeNotControlled:        "Units may only be built on supply centers the country controls.",
+	OutcomeOccupied:             "Units cannot be built, or retreat, where a unit already is.",
+	OutcomeBadConvoy:            "Only fleets at sea may convoy.",
+}
+
+// Explain traces why a country's order has its outcome, as given by [Arena.Query].
+//
+// In a move phase, the trace includes the strengths that competed, the supports
+// that counted and those that were cut, and the convoys used. For illegal orders
+// and in other phases, only the rule applied is given.
+func (a *Arena) Explain(country string, order Order) *Explanation {
+	e := &Explanation{
+		game:    a.game,
+		Country: country,
+		Order:   order,
+		Outcome: a.Query(country, order),
+	}
+	e.Rule = outcomeRules[e.Outcome]
+	if !a.game.phase.Move() || !outcomeAssigned(e.Outcome) {
+		return e
+	}
+	unit := a.game.Unit(order.Unit)
+	if a.legal(unit, order) != OutcomeSuccess {
+		return e
+	}
+	orders := a.orders()
+	orders[unit] = order
+	r := newResolver(a.game, orders, a.rules)
+	r.explain(e, unit, true)
+	return e
+}
+
+// explain fills in the trace of a unit's order, and of the orders it
+// depended on if details is set.
+func (r *resolver) explain(e *Explanation, u *Occupancy, details bool) {
+	o := r.orders[u]
+	switch o.Kind() {
+	case MoveRetreat:
+		ss, _ := r.attackSupporters(u)
+		e.Force = &Force{u, o, ForceAttack, r.attackStrength(u), ss}
+		if opp := r.headToHead(u); opp != nil {
+			e.Opposition = append(e.Opposition, r.force(opp, ForceDefend))
+		} else if d := r.game.units[o.Target]; d != nil {
+			e.Opposition = append(e.Opposition, r.force(d, ForceHold))
+		}
+		for _, m := range r.into[o.Target] {
+			if m != u {
+				e.Opposition = append(e.Opposition, r.force(m, ForcePrevent))
+			}
+		}
+		e.Convoyed = r.convoyed[u]
+		if e.Convoyed && !r.paradox[u] {
+			e.Convoy = r.route(u, r.resolve)
+		}
+	default:
+		e.Force = r.force(u, ForceHold)
+		for _, m := range r.into[u.province] {
+			e.Opposition = append(e.Opposition, r.force(m, ForceAttack))
+		}
+		if o.Kind() == SupportHold || o.Kind() == SupportMove {
+			for _, m := range r.into[u.province] {
+				if r.cuts(m, u, o) {
+					e.CutBy = append(e.CutBy, m)
+				}
+			}
+		}
+	}
+	e.DislodgedBy = r.dislodger(u)
+	e.Rule = r.rule(u, e.Outcome)
+	if !details {
+		return
+	}
+	// Supports and convoys that did not help.
+	var failed []*Occupancy
+	for _, s := range r.support[u] {
+		if !r.resolve(s) {
+			failed = append(failed, s)
+		}
+	}
+	if e.Convoyed && e.Convoy == nil {
+		for _, f := range r.units {
+			fo := r.orders[f]
+			if fo.Kind() == Convoy && fo.Recipient == u.province && fo.Target == o.Target && !r.resolve(f) {
+				failed = append(failed, f)
+			}
+		}
+	}
+	for _, f := range failed {
+		d := &Explanation{
+			game:    r.game,
+			Country: f.country,
+			Order:   r.orders[f],
+			Outcome: r.outcome(f),
+		}
+		r.explain(d, f, false)
+		e.Details = append(e.Details, d)
+	}
+}
+
+// force measures the strength of a unit's order.
+func (r *resolver) force(u *Occupancy, kind ForceKind) *Force {
+	f := &Force{Unit: u, Order: r.orders[u], Kind: kind}
+	switch kind {
+	case ForceAttack:
+		f.Supporters, _ = r.attackSupporters(u)
+		f.Strength = r.attackStrength(u)
+	case ForceHold:
+		f.Strength = r.holdStrength(u.province)
+		if f.Order.Kind() != MoveRetreat {
+			f.Supporters = r.supporters(u, "")
+		}
+	case ForceDefend:
+		f.Supporters = r.supporters(u, "")
+		f.Strength = r.defendStrength(u)
+	case ForcePrevent:
+		f.Strength = r.preventStrength(u)
+		if f.Strength > 0 {
+			f.Supporters = r.supporters(u, "")
+		}
+	}
+	return f
+}
+
+// rule describes the rule that gave a unit's order its outcome.
+func (r *resolver) rule(u *Occupancy, outcome Outcome) string {
+	o := r.orders[u]
+	switch o.Kind() {
+	case MoveRetreat:
+		switch outcome {
+		case OutcomeSuccess:
+			return "A move succeeds when its attack is stronger than the unit in its target " +
+				"and every other unit moving there."
+		case OutcomeNoConvoy:
+			if r.paradox[u] {
+				return fmt.Sprintf("The convoy is caught in a paradox; by the %v rule, "+
+					"the army does not move.", r.rules.Paradox)
+			}
+		case OutcomeWeak:
+			if d := r.defender(u); d != nil && d.country == u.country {
+				return "A country cannot dislodge its own unit."
+			}
+			if r.headToHead(u) != nil {
+				return "A move fails when its attack is not stronger than the defense " +
+					"of the unit moving the opposite way."
+			}
+		}
+	case SupportHold, SupportMove:
+		if outcome == OutcomeSuccess {
+			return "A support succeeds unless it is cut or the supporting unit is dislodged."
+		}
+	case Convoy:
+		switch {
+		case outcome == OutcomeSuccess:
+			return "A convoy succeeds unless the convoying fleet is dislodged."
+		case r.failed[u]:
+			return fmt.Sprintf("The convoy is caught in a paradox; by the %v rule, it fails.",
+				r.rules.Paradox)
+		}
+	default:
+		if outcome == OutcomeSuccess {
+			return "A unit holds unless another unit's attack is stronger than its hold."
+		}
+	}
+	return outcomeRules[outcome]
+}
+
+// unitLabel names a unit by its type and province, like "F Lon".
+func unitLabel(u *Occupancy) string {
+	if u == nil {
+		return "?"
+	}
+	kind := "A"
+	if u.unit == Fleet {
+		kind = "F"
+	}
+	return kind + " " + provinceLabel(u.province)
+}
+
+func provinceLabel(p *Province) string {
+	if p == nil {
+		return "?"
+	}
+	return p.abbrs[0]
+}
+
+// String is the strength as text, like "attack 2 (A Bur - Mun, supported by A Ruh)".
+func (f *Force) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v %d", f.Kind, f.Strength)
+	if f.Unit == nil {
+		return sb.String()
+	}
+	// Units are labeled by their province, which does not need the game.
+	fmt.Fprintf(&sb, " (%s", unitLabel(f.Unit))
+	if len(f.Supporters) > 0 {
+		sb.WriteString(", supported by ")
+		sb.WriteString(unitLabels(f.Supporters))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+func unitLabels(units []*Occupancy) string {
+	labels := make([]string, len(units))
+	for i, u := range units {
+		labels[i] = unitLabel(u)
+	}
+	return strings.Join(labels, ", ")
+}
+
+// String is the trace as indented, human-readable text.
+func (e *Explanation) String() string {
+	var sb strings.Builder
+	e.write(&sb, "")
+	return sb.String()
+}
+
+func (e *Explanation) write(sb *strings.Builder, indent string) {
+	fmt.Fprintf(sb, "%s%s: %v\n", indent, e.Order.Format(e.game, StyleWebDip), e.Outcome)
+	indent += "  "
+	fmt.Fprintf(sb, "%s%s\n", indent, e.Rule)
+	if e.Force != nil {
+		fmt.Fprintf(sb, "%s%v\n", indent, e.Force)
+	}
+	for _, f := range e.Opposition {
+		fmt.Fprintf(sb, "%sagainst %v\n", indent, f)
+	}
+	if e.Convoyed {
+		if e.Convoy == nil {
+			fmt.Fprintf(sb, "%sconvoy broken\n", indent)
+		} else {
+			labels := make([]string, len(e.Convoy))
+			for i, p := range e.Convoy {
+				labels[i] = provinceLabel(p)
+			}
+			fmt.Fprintf(sb, "%sconvoyed through %s\n", indent, strings.Join(labels, ", "))
+		}
+	}
+	if len(e.CutBy) > 0 {
+		fmt.Fprintf(sb, "%scut by %s\n", indent, unitLabels(e.CutBy))
+	}
+	if e.DislodgedBy != nil {
+		fmt.Fprintf(sb, "%sdislodged by %s\n", indent, unitLabel(e.DislodgedBy))
+	}
+	for _, d := range e.Details {
+		d.write(sb, indent)
+	}
+}
@@ -0,0 +1,140 @@
+package diplo
+
+import (
+	"slices"
+	"testing"
+)
+
+// explainOrder sets up a position, gives each country's orders, and explains
+// one of them.
+func explainOrder(t *testing.T, notation string, rules RuleSet, orders map[string][]string, country, order string) (*Game, *Explanation) {
+	t.Helper()
+	g, err := ParseNotation(StandardBoard, notation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetRules(rules)
+	a := g.Arena()
+	for c, texts := range orders {
+		for _, text := range texts {
+			if _, err := a.Add(c, mustParseOrder(t, g, c, text)); err != nil {
+				t.Fatalf("Add(%q): %v", text, err)
+			}
+		}
+	}
+	return g, a.Explain(country, mustParseOrder(t, g, country, order))
+}
+
+func unitAt(g *Game, abbr string) *Occupancy {
+	return g.Unit(StandardBoard.ParseProvince(abbr)[0])
+}
+
+func TestExplainStandoff(t *testing.T) {
+	g, e := explainOrder(t, "S1901M F:APar,APic;G:AMun,ARuh;SC:", RuleSet{}, map[string][]string{
+		"France":  {"A Par - Bur", "A Pic S A Par - Bur"},
+		"Germany": {"A Mun - Bur", "A Ruh S A Mun - Bur"},
+	}, "France", "A Par - Bur")
+	if e.Outcome != OutcomeStandoff {
+		t.Fatalf("Outcome = %v, want Standoff", e.Outcome)
+	}
+	if e.Rule != outcomeRules[OutcomeStandoff] {
+		t.Errorf("Rule = %q", e.Rule)
+	}
+	if e.Force == nil || e.Force.Kind != ForceAttack || e.Force.Strength != 2 ||
+		!slices.Equal(e.Force.Supporters, []*Occupancy{unitAt(g, "Pic")}) {
+		t.Errorf("Force = %v, want attack 2 supported by A Pic", e.Force)
+	}
+	if len(e.Opposition) != 1 {
+		t.Fatalf("Opposition = %v, want one prevent", e.Opposition)
+	}
+	if got, want := e.Opposition[0].String(), "prevent 2 (A Mun, supported by A Ruh)"; got != want {
+		t.Errorf("Opposition[0] = %q, want %q", got, want)
+	}
+	if e.Convoyed || e.DislodgedBy != nil || len(e.Details) != 0 {
+		t.Errorf("unexpected trace:\n%v", e)
+	}
+}
+
+func TestExplainCut(t *testing.T) {
+	g, e := explainOrder(t, "S1901M F:APar,APic;E:ABel;SC:", RuleSet{}, map[string][]string{
+		"France":  {"A Par - Bur", "A Pic S A Par - Bur"},
+		"England": {"A Bel - Pic"},
+	}, "France", "A Pic S A Par - Bur")
+	if e.Outcome != OutcomeCut {
+		t.Fatalf("Outcome = %v, want Cut", e.Outcome)
+	}
+	if !slices.Equal(e.CutBy, []*Occupancy{unitAt(g, "Bel")}) {
+		t.Errorf("CutBy = %v, want A Bel", unitLabels(e.CutBy))
+	}
+	if e.DislodgedBy != nil {
+		t.Errorf("DislodgedBy = %v, want nil", unitLabel(e.DislodgedBy))
+	}
+
+	// The move explains the support that did not count.
+	g, e = explainOrder(t, "S1901M F:APar,APic;E:ABel;SC:", RuleSet{}, map[string][]string{
+		"France":  {"A Par - Bur", "A Pic S A Par - Bur"},
+		"England": {"A Bel - Pic"},
+	}, "France", "A Par - Bur")
+	if e.Force.Strength != 1 || len(e.Force.Supporters) != 0 {
+		t.Errorf("Force = %v, want attack 1 unsupported", e.Force)
+	}
+	if len(e.Details) != 1 || e.Details[0].Outcome != OutcomeCut ||
+		!slices.Equal(e.Details[0].CutBy, []*Occupancy{unitAt(g, "Bel")}) {
+		t.Errorf("Details do not give the cut support:\n%v", e)
+	}
+}
+
+func TestExplainConvoy(t *testing.T) {
+	g, e := explainOrder(t, "S1901M E:ALon,FNTH;SC:", RuleSet{}, map[string][]string{
+		"England": {"A Lon - Nwy", "F NTH C A Lon - Nwy"},
+	}, "England", "A Lon - Nwy")
+	if e.Outcome != OutcomeSuccess {
+		t.Fatalf("Outcome = %v, want Success", e.Outcome)
+	}
+	if !e.Convoyed || !slices.Equal(e.Convoy, StandardBoard.ParseProvince("NTH")) {
+		t.Errorf("Convoyed = %v, Convoy = %v, want through NTH", e.Convoyed, e.Convoy)
+	}
+
+	// Dislodging the fleet breaks the convoy.
+	g, e = explainOrder(t, "S1901M E:ALon,FNTH;F:FBel,FENG;SC:", RuleSet{}, map[string][]string{
+		"England": {"A Lon - Nwy", "F NTH C A Lon - Nwy"},
+		"France":  {"F Bel - NTH", "F ENG S F Bel - NTH"},
+	}, "England", "A Lon - Nwy")
+	if e.Outcome != OutcomeNoConvoy {
+		t.Fatalf("Outcome = %v, want NoConvoy", e.Outcome)
+	}
+	if !e.Convoyed || e.Convoy != nil {
+		t.Errorf("Convoyed = %v, Convoy = %v, want a broken convoy", e.Convoyed, e.Convoy)
+	}
+	if len(e.Details) != 1 || e.Details[0].Outcome != OutcomeDislodged ||
+		e.Details[0].DislodgedBy != unitAt(g, "Bel") {
+		t.Errorf("Details do not give the dislodged fleet:\n%v", e)
+	}
+}
+
+func TestExplainParadox(t *testing.T) {
+	// Pandin's paradox (DATC 6.F.16).
+	const notation = "S1901M E:FLon,FWal;F:ABre,FENG;G:FNTH,FBel;SC:"
+	orders := map[string][]string{
+		"England": {"F Lon S F Wal - ENG", "F Wal - ENG"},
+		"France":  {"A Bre - Lon", "F ENG C A Bre - Lon"},
+		"Germany": {"F NTH S F Bel - ENG", "F Bel - ENG"},
+	}
+	tests := []struct {
+		rules   RuleSet
+		order   string
+		outcome Outcome
+		rule    string
+	}{
+		{RuleSet{}, "A Bre - Lon", OutcomeNoConvoy,
+			"The convoy is caught in a paradox; by the Szykman rule, the army does not move."},
+		{RuleSet{Paradox: Paradox2000}, "F ENG C A Bre - Lon", OutcomeCut,
+			"The convoy is caught in a paradox; by the 2000 rule, it fails."},
+	}
+	for _, tt := range tests {
+		_, e := explainOrder(t, notation, tt.rules, orders, "France", tt.order)
+		if e.Outcome != tt.outcome || e.Rule != tt.rule {
+			t.Errorf("%v: %s => %v, %q; want %v, %q", tt.rules.Paradox, tt.order, e.Outcome, e.Rule, tt.outcome, tt.rule)
+		}
+	}
+}
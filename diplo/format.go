@@ -0,0 +1,225 @@
+package diplo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Style is a way of writing orders as text (see [Order.Format]).
+type Style int
+
+const (
+	// StyleLong writes full province names, like "A Paris - Burgundy" and
+	// "F Mid-Atlantic Ocean - Spain (NC)".
+	StyleLong Style = iota
+	// StyleShort writes province abbreviations with no space around moves,
+	// like "A Par-Bur" and "F MAO-Spa(NC)".
+	StyleShort
+	// StyleWebDip writes province abbreviations with space around moves, as
+	// webDiplomacy does, like "A Par S A Mar - Bur" and "F MAO - Spa(NC)".
+	StyleWebDip
+	// StyleDAIDE writes the tokens of the DAIDE protocol, like
+	// "( ( FRA AMY PAR ) MTO BUR )".
+	StyleDAIDE
+)
+
+var styleNames = [...]string{
+	StyleLong:   "Long",
+	StyleShort:  "Short",
+	StyleWebDip: "WebDip",
+	StyleDAIDE:  "DAIDE",
+}
+
+// String is the name of the style without its "Style" prefix, e.g. "WebDip".
+func (s Style) String() string {
+	if s < 0 || int(s) >= len(styleNames) {
+		return fmt.Sprintf("Style(%d)", int(s))
+	}
+	return styleNames[s]
+}
+
+// ParseStyle interprets the name of a style, as given by [Style.String].
+// Case is ignored.
+func ParseStyle(name string) (Style, bool) {
+	for s, n := range styleNames {
+		if strings.EqualFold(n, name) {
+			return Style(s), true
+		}
+	}
+	return 0, false
+}
+
+// Format writes the order as text in a style. The game gives the units
+// ordered (including dislodged units in retreat phases) and the phase, which
+// decides whether a hold is written as a disband. Units not in the game are
+// written by their province alone.
+//
+// Every style but [StyleDAIDE] is accepted by [Game.ParseOrder]. Holds are
+// written with H (or Hold), disbands with D (or Disband), builds like
+// "Build F StP(NC)", and waives as "Waive". A move that must go by convoy
+// ends with "via convoy".
+//
+// In [StyleDAIDE], moves through a convoy name the shortest chain of fleets
+// in the game, and a waive, which does not say its country, is written as
+// "WVE" alone; use [Order.DAIDE] to include it.
+func (o Order) Format(game *Game, style Style) string {
+	if style == StyleDAIDE {
+		return o.formatDAIDE(game, "")
+	}
+	f := formatter{game, style}
+	var s string
+	switch o.Kind() {
+	case HoldDisband:
+		switch {
+		case style == StyleLong && game.phase.Move():
+			s = f.unit(o.Unit) + " Hold"
+		case style == StyleLong:
+			s = f.unit(o.Unit) + " Disband"
+		case game.phase.Move():
+			s = f.unit(o.Unit) + " H"
+		default:
+			s = f.unit(o.Unit) + " D"
+		}
+	case MoveRetreat:
+		s = f.unit(o.Unit) + f.move() + f.target(o.Target, o.TargetCoast)
+		if o.ViaConvoy {
+			s += " via convoy"
+		}
+	case SupportHold:
+		s = f.unit(o.Unit) + " S " + f.unit(o.Recipient)
+	case SupportMove:
+		s = f.unit(o.Unit) + " S " + f.unit(o.Recipient) + f.move() + f.target(o.Target, o.TargetCoast)
+	case Convoy:
+		s = f.unit(o.Unit) + " C " + f.unit(o.Recipient) + f.move() + f.target(o.Target, o.TargetCoast)
+	case Build:
+		s = "Build " + o.Build.String()[:1] + " " + f.target(o.Target, o.TargetCoast)
+	case Waive:
+		s = "Waive"
+	default:
+		s = "?"
+	}
+	return s
+}
+
+type formatter struct {
+	game  *Game
+	style Style
+}
+
+func (f formatter) province(p *Province) string {
+	if p == nil {
+		return "?"
+	}
+	if f.style == StyleLong {
+		return p.name
+	}
+	return p.abbrs[0]
+}
+
+func (f formatter) unit(p *Province) string {
+	u := f.game.orderedUnit(p)
+	if u == nil {
+		return f.province(p)
+	}
+	return u.unit.String()[:1] + " " + f.province(p)
+}
+
+func (f formatter) target(p *Province, coast string) string {
+	switch {
+	case coast == "":
+		return f.province(p)
+	case f.style == StyleLong:
+		return f.province(p) + " (" + coast + ")"
+	default:
+		return f.province(p) + "(" + coast + ")"
+	}
+}
+
+func (f formatter) move() string {
+	if f.style == StyleShort {
+		return "-"
+	}
+	return " - "
+}
+
+// formatDAIDE writes the order as DAIDE tokens, with the country of a waive
+// if given.
+func (o Order) formatDAIDE(game *Game, country string) string {
+	b := game.board
+	unit := func(p *Province) string {
+		u := game.orderedUnit(p)
+		if u == nil {
+			return b.DAIDEProvince(p)
+		}
+		return daideUnitAt(b, u.country, u.unit, p, u.coast)
+	}
+	target := func() string {
+		if o.TargetCoast == "" {
+			return b.DAIDEProvince(o.Target)
+		}
+		return "( " + b.DAIDEProvince(o.Target) + " " + daideCoast(o.TargetCoast) + " )"
+	}
+	switch o.Kind() {
+	case HoldDisband:
+		switch {
+		case game.phase.Move():
+			return "( " + unit(o.Unit) + " HLD )"
+		case game.phase.Retreat():
+			return "( " + unit(o.Unit) + " DSB )"
+		default:
+			return "( " + unit(o.Unit) + " REM )"
+		}
+	case MoveRetreat:
+		if game.phase.Retreat() {
+			return "( " + unit(o.Unit) + " RTO " + target() + " )"
+		}
+		if u := game.Unit(o.Unit); u != nil && u.unit == Army &&
+			(o.ViaConvoy || b.Connection(o.Unit, o.Target) == nil) {
+			var shortest []*Province
+			for _, chain := range game.ConvoyChains(o.Unit, o.Target) {
+				if shortest == nil || len(chain) < len(shortest) {
+					shortest = chain
+				}
+			}
+			if shortest != nil {
+				via := make([]string, len(shortest))
+				for i, p := range shortest {
+					via[i] = b.DAIDEProvince(p)
+				}
+				return "( " + unit(o.Unit) + " CTO " + b.DAIDEProvince(o.Target) +
+					" VIA ( " + strings.Join(via, " ") + " ) )"
+			}
+		}
+		return "( " + unit(o.Unit) + " MTO " + target() + " )"
+	case SupportHold:
+		return "( " + unit(o.Unit) + " SUP " + unit(o.Recipient) + " )"
+	case SupportMove:
+		return "( " + unit(o.Unit) + " SUP " + unit(o.Recipient) + " MTO " + b.DAIDEProvince(o.Target) + " )"
+	case Convoy:
+		return "( " + unit(o.Unit) + " CVY " + unit(o.Recipient) + " CTO " + b.DAIDEProvince(o.Target) + " )"
+	case Build:
+		if country == "" {
+			country = game.centers[o.Target]
+		}
+		if country == "" {
+			country = o.Target.country
+		}
+		return "( " + daideUnitAt(b, country, o.Build, o.Target, o.TargetCoast) + " BLD )"
+	case Waive:
+		if country == "" {
+			return "WVE"
+		}
+		return "( " + DAIDECountry(country) + " WVE )"
+	default:
+		return "?"
+	}
+}
+
+// daideUnitAt writes a unit as DAIDE tokens, like "( RUS FLT ( STP NCS ) )".
+func daideUnitAt(b *Board, country string, unit Unit, p *Province, coast string) string {
+	where := b.DAIDEProvince(p)
+	if unit == Fleet && coast != "" {
+		where = "( " + where + " " + daideCoast(coast) + " )"
+	}
+	return "( " + DAIDECountry(country) + " " + daideUnit(unit) + " " + where + " )"
+}
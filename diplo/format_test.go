@@ -0,0 +1,80 @@
+package diplo
+
+import "testing"
+
+// roundTripPositions are positions in each kind of phase, with convoys,
+// split coasts, and a unit dislodged by an attacker now in its province.
+var roundTripPositions = []string{
+	"S1901M A:ABud,FTri,AVie;E:FEdi,ALvp,FLon;F:FBre,AMar,APar;G:FKie,ABer,AMun;" +
+		"I:FNap,ARom,AVen;R:AMos,FSev,FStP/SC,AWar;T:FAnk,ACon,ASmy;SC:",
+	"F1901M E:FENG,ALon,FNTH,AWal;F:FMAO,APor,FGoL;T:FBla,ABul,FAEG;SC:",
+	"F1901R E:FNTH;T:ABul,FBla;D:FBul/EC=E<Con",
+	"W1901A E:FNTH,AYor;R:FStP/NC,AMos,ASev;SC:Lon=E,Edi=E,Lvp=E,Nwy=E,StP=R,Mos=R,Sev=R,War=R,Rum=R",
+}
+
+// roundTripOrders is every legal order in a game, with the country giving it.
+func roundTripOrders(g *Game) map[Order]string {
+	orders := make(map[Order]string)
+	units := g.AllUnits()
+	if g.phase.Retreat() {
+		units = g.AllDislodged()
+	}
+	for u := range units {
+		for _, o := range g.LegalOrders(u) {
+			orders[o] = u.country
+		}
+	}
+	for _, c := range g.board.countries {
+		for _, o := range g.LegalAdjustments(c) {
+			orders[o] = c
+		}
+	}
+	return orders
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	for _, notation := range roundTripPositions {
+		g, err := ParseNotation(StandardBoard, notation)
+		if err != nil {
+			t.Fatalf("%s: %v", notation, err)
+		}
+		orders := roundTripOrders(g)
+		if len(orders) == 0 {
+			t.Fatalf("%s: no legal orders", notation)
+		}
+		for o, country := range orders {
+			for _, style := range []Style{StyleLong, StyleShort, StyleWebDip} {
+				text := o.Format(g, style)
+				parsed, err := g.ParseOrder(text, country)
+				if err != nil {
+					t.Errorf("%s: ParseOrder(%q): %v", g.Notation(), text, err)
+				} else if *parsed != o {
+					t.Errorf("%s: ParseOrder(%q) = %+v, want %+v", g.Notation(), text, *parsed, o)
+				}
+			}
+			text := o.DAIDE(g, country)
+			c, parsed, err := g.ParseDAIDEOrder(text)
+			if err != nil {
+				t.Errorf("%s: ParseDAIDEOrder(%q): %v", g.Notation(), text, err)
+			} else if c != country || *parsed != o {
+				t.Errorf("%s: ParseDAIDEOrder(%q) = %s %+v, want %s %+v", g.Notation(), text, c, *parsed, country, o)
+			}
+		}
+	}
+}
+
+func TestFormatDislodged(t *testing.T) {
+	g, err := ParseNotation(StandardBoard, "F1901R E:FNTH;T:ABul,FBla;D:FBul/EC=E<Con")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := OrderMoveRetreat(StandardBoard.ParseProvince("Bul")[0], StandardBoard.ParseProvince("Rum")[0], "")
+	for style, want := range map[Style]string{
+		StyleWebDip: "F Bul - Rum",
+		StyleDAIDE:  "( ( ENG FLT ( BUL ECS ) ) RTO RUM )",
+	} {
+		if got := o.Format(g, style); got != want {
+			t.Errorf("Format(%v) = %q, want %q", style, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package diplo
 
 import (
 	"errors"
+	"fmt"
 	"iter"
 	"maps"
 	"slices"
@@ -31,6 +32,33 @@ const (
 	Winter
 )
 
+var phaseNames = [...]string{
+	Spring:         "Spring",
+	SpringRetreats: "SpringRetreats",
+	Fall:           "Fall",
+	FallRetreats:   "FallRetreats",
+	Winter:         "Winter",
+}
+
+// String is the name of the phase, e.g. "FallRetreats".
+func (p Phase) String() string {
+	if p < 0 || int(p) >= len(phaseNames) {
+		return fmt.Sprintf("Phase(%d)", int(p))
+	}
+	return phaseNames[p]
+}
+
+// ParsePhase interprets the name of a phase, as given by [Phase.String].
+// Case is ignored.
+func ParsePhase(name string) (Phase, bool) {
+	for p, n := range phaseNames {
+		if strings.EqualFold(n, name) {
+			return Phase(p), true
+		}
+	}
+	return 0, false
+}
+
 // Move tells whether it is a move phase ([Spring] or [Fall]).
 func (p Phase) Move() bool {
 	return p == Spring || p == Fall
@@ -85,6 +113,8 @@ type Game struct {
 	phase   Phase
 	units   map[*Province]*Occupancy
 	centers map[*Province]string
+	rules   RuleSet
+	suggest bool // parsing suggests misspelled provinces
 	// Used for retreats
 	dislodged map[*Province]*Occupancy // dislodged units
 	contests  map[*Province]bool       // cannot retreat here
@@ -94,7 +124,7 @@ type Game struct {
 // NewGame creates a fresh game state from the specified board.
 //
 // Important: each country will automatically control its home supply centers,
-// but the starting units must be added manually. See [StandardGameSetup].
+// but the starting units must be added manually. See [NewStartingGame].
 //
 // The game starts in [Spring] of [StartYear].
 func NewGame(board *Board) *Game {
@@ -115,6 +145,32 @@ func NewGame(board *Board) *Game {
 func (g *Game) resetRetreats() {
 	g.dislodged = make(map[*Province]*Occupancy)
 	g.contests = make(map[*Province]bool)
+	g.attackers = make(map[*Occupancy]*Province)
+}
+
+// Rules is the set of variant rules the game is played with.
+// It is the standard rules unless set otherwise.
+func (g *Game) Rules() RuleSet {
+	return g.rules
+}
+
+// SetRules changes the variant rules the game is played with.
+// The rules carry over to the games that follow.
+func (g *Game) SetRules(rules RuleSet) {
+	g.rules = rules
+}
+
+// SetSuggestions turns on or off suggestions for misspelled provinces when
+// parsing orders: an [UnknownProvinceError] then lists the provinces the
+// name is closest to (see [Board.MatchProvince]). It is off unless set, and
+// carries over to the games that follow.
+func (g *Game) SetSuggestions(on bool) {
+	g.suggest = on
+}
+
+// convoyable tells whether a Fleet in the province may convoy.
+func (g *Game) convoyable(p *Province) bool {
+	return p.terrain == Water || g.rules.ConvoyViaCoasts && p.terrain == Coastal
 }
 
 // Board is the geographical layout the game uses.
@@ -197,6 +253,26 @@ func (g *Game) CenterCount(country string) int {
 	return count(g.Centers(country))
 }
 
+// VictoryCenters is how many supply centers a country needs to win
+// (see [RuleSet.VictoryCenters]).
+func (g *Game) VictoryCenters() int {
+	if g.rules.VictoryCenters > 0 {
+		return g.rules.VictoryCenters
+	}
+	return count(g.board.Centers())/2 + 1
+}
+
+// Winner gets the country that controls enough supply centers to win, if any.
+func (g *Game) Winner() (string, bool) {
+	need := g.VictoryCenters()
+	for _, c := range g.board.countries {
+		if g.CenterCount(c) >= need {
+			return c, true
+		}
+	}
+	return "", false
+}
+
 // OpenHomeCenters gets all of a country's home supply centers
 // that they control and are not currently occupied by a unit.
 func (g *Game) OpenHomeCenters(country string) iter.Seq[*Province] {
@@ -224,9 +300,7 @@ func (g *Game) OpenHomeCenterCount(country string) int {
 // Contested is which provinces had a standoff in the previous
 // [Spring] or [Fall] phase; these may not be retreated to.
 func (g *Game) Contested() iter.Seq[*Province] {
-	if !g.phase.Retreat() {
-		return nil
-	}
+	// Only ever set during retreat phases.
 	return maps.Keys(g.contests)
 }
 
@@ -236,16 +310,24 @@ func (g *Game) DislodgedUnit(province *Province) *Occupancy {
 	return g.dislodged[province]
 }
 
-// Dislodged is all the units that were dislodged in a prior
+// orderedUnit is the unit an order for the province is given to: the
+// dislodged unit in retreat phases, if there is one, and otherwise the
+// unit there.
+func (g *Game) orderedUnit(province *Province) *Occupancy {
+	if u := g.dislodged[province]; u != nil && g.phase.Retreat() {
+		return u
+	}
+	return g.units[province]
+}
+
+// AllDislodged is all the units that were dislodged in a prior
 // move phase and need to retreat or disband.
 //
 // The [Occupancy] values represent how things were in the prior phase;
 // the unit must retreat to an adjacent province or disband. It does
 // not mean a unit is actually present there for any other purpose.
 func (g *Game) AllDislodged() iter.Seq[*Occupancy] {
-	if !g.phase.Retreat() {
-		return nil
-	}
+	// Only ever set during retreat phases.
 	return maps.Values(g.dislodged)
 }
 
@@ -313,9 +395,13 @@ func (g *Game) convoyChains(
 	for c := range g.board.ConnectionsFrom(last) {
 		to := c.to
 		if to == dest {
-			return append(chains, base[1:])
+			// A direct connection is not a convoy.
+			if baseLength > 1 {
+				chains = append(chains, base[1:])
+			}
+			continue
 		}
-		if to.terrain != Water {
+		if !g.convoyable(to) {
 			continue
 		}
 		if o, ok := g.units[to]; !ok || o.unit != Fleet {
@@ -325,7 +411,7 @@ func (g *Game) convoyChains(
 			continue
 		}
 		next := make([]*Province, baseLength+1)
-		copy(base, next[:baseLength])
+		copy(next, base)
 		next[baseLength] = to
 		chains = g.convoyChains(chains, next, dest)
 	}
@@ -352,10 +438,10 @@ func (g *Game) HasDestination(unit *Occupancy, destination *Province) bool {
 		return false
 	}
 	if c := g.board.Connection(unit.province, destination); c != nil {
-		return c.Traversable(unit.unit)
+		return traversable(unit, c)
 	}
 	// Convoy routes.
-	if unit.province.terrain != Coastal || destination.terrain != Coastal {
+	if unit.unit != Army || unit.province.terrain != Coastal || destination.terrain != Coastal {
 		return false
 	}
 	var (
@@ -371,16 +457,15 @@ func (g *Game) HasDestination(unit *Occupancy, destination *Province) bool {
 				if to == destination {
 					return true
 				}
-				if visited[to] || to.terrain != Water {
+				if visited[to] || !g.convoyable(to) {
 					continue
 				}
 				visited[to] = true
 				// Fleet must be there, to perform convoy.
-				if g.Unit(to) == nil {
+				if f := g.Unit(to); f == nil || f.unit != Fleet {
 					continue
 				}
-				nodes = append(nodes, to)
-				continue
+				next = append(next, to)
 			}
 		}
 	}
@@ -402,7 +487,7 @@ func (g *Game) Destinations(unit *Occupancy) iter.Seq[*Province] {
 			}
 		}
 		// Follow Fleets to potential convoy destinations.
-		if unit.province.terrain != Coastal {
+		if unit.unit != Army || unit.province.terrain != Coastal {
 			return
 		}
 		var (
@@ -419,17 +504,15 @@ func (g *Game) Destinations(unit *Occupancy) iter.Seq[*Province] {
 						continue
 					}
 					visited[to] = true
-					if to.terrain == Water {
-						// Fleet must be there, to perform convoy.
-						if g.Unit(to) == nil {
-							continue
+					if to.terrain == Coastal && g.board.Connection(unit.province, to) == nil {
+						// Coastal endpoint found, not already a neighbor.
+						if !yield(to) {
+							return
 						}
-						nodes = append(nodes, to)
-						continue
 					}
-					// Coastal endpoint found.
-					if !yield(to) {
-						return
+					// Fleet must be there, to perform convoy.
+					if f := g.Unit(to); g.convoyable(to) && f != nil && f.unit == Fleet {
+						next = append(next, to)
 					}
 				}
 			}
@@ -437,13 +520,25 @@ func (g *Game) Destinations(unit *Occupancy) iter.Seq[*Province] {
 	}
 }
 
+// traversable tells whether a unit can cross a connection from its province,
+// including from the coast a Fleet is on.
+func traversable(unit *Occupancy, c *Connection) bool {
+	if !c.Traversable(unit.unit) {
+		return false
+	}
+	if unit.unit == Fleet && unit.coast != "" && len(c.fromCoasts) > 0 {
+		return hasStringFold(c.fromCoasts, unit.coast)
+	}
+	return true
+}
+
 // HasNeighbor determines whether a unit can travel to the adjancent destination.
 func (g *Game) HasNeighbor(unit *Occupancy, destination *Province) bool {
 	c := g.board.Connection(unit.province, destination)
 	if c == nil {
 		return false
 	}
-	return c.Traversable(unit.unit)
+	return traversable(unit, c)
 }
 
 // Neighbors gets which adjacent provinces a unit can travel to.
@@ -453,7 +548,7 @@ func (g *Game) Neighbors(unit *Occupancy) iter.Seq[*Province] {
 	}
 	return func(yield func(*Province) bool) {
 		for c := range g.board.ConnectionsFrom(unit.province) {
-			if !c.Traversable(unit.unit) {
+			if !traversable(unit, c) {
 				continue
 			}
 			if !yield(c.to) {
@@ -470,7 +565,7 @@ func (g *Game) validSetUnit(p *Province, cs string, u Unit, cn string) (*Occupan
 	if err := g.board.validCountry(cn); err != nil {
 		return nil, err
 	}
-	if p.terrain == Coastal && u == Fleet {
+	if p.terrain == Coastal && u == Fleet && len(p.coasts) > 0 {
 		if err := p.validCoast(cs); err != nil {
 			return nil, err
 		}
@@ -490,12 +585,89 @@ func (g *Game) validSetUnit(p *Province, cs string, u Unit, cn string) (*Occupan
 func (g *Game) SetUnit(province *Province, coast string, unit Unit, country string) error {
 	occ, err := g.validSetUnit(province, coast, unit, country)
 	if err != nil {
-		return nil
+		return err
 	}
 	g.units[province] = occ
 	return nil
 }
 
+// NewStartingGame returns the Spring 1901 game state for a board, with its
+// starting units placed (see [Board.StartingUnits]).
+//
+// Each unit is placed as with [Game.SetUnit], and if the board has starting
+// units, the position is checked with [Game.ValidateSetup].
+func NewStartingGame(board *Board) (*Game, error) {
+	g := NewGame(board)
+	if err := g.placeStartingUnits(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// placeStartingUnits places the board's starting units and validates them.
+func (g *Game) placeStartingUnits() error {
+	for u := range g.board.StartingUnits() {
+		if err := g.SetUnit(u.province, u.coast, u.unit, u.country); err != nil {
+			return err
+		}
+	}
+	if len(g.board.units) == 0 {
+		// Set up by hand.
+		return nil
+	}
+	return g.validateHomes()
+}
+
+// ValidateSetup checks that the game is in a starting position. If the
+// board declares its starting units (see [Board.StartingUnits]), the game
+// must have exactly those units, with the same types and coasts. Otherwise,
+// every country must have exactly one unit on each of its home supply
+// centers, and no units elsewhere.
+func (g *Game) ValidateSetup() error {
+	if len(g.board.units) == 0 {
+		return g.validateHomes()
+	}
+	for _, want := range g.board.units {
+		u := g.units[want.province]
+		switch {
+		case u == nil:
+			return fmt.Errorf("%s has no %s in %s", want.country, want.unit, want.province.name)
+		case u.country != want.country || u.unit != want.unit:
+			return fmt.Errorf("%s has %s %s, want %s %s",
+				want.province.name, u.country, u.unit, want.country, want.unit)
+		case u.coast != want.coast:
+			return fmt.Errorf("%s %s in %s is on coast %q, want %q",
+				u.country, u.unit, want.province.name, u.coast, want.coast)
+		}
+	}
+	if n := len(g.units); n != len(g.board.units) {
+		return fmt.Errorf("game has %d units but the board starts with %d", n, len(g.board.units))
+	}
+	return nil
+}
+
+// validateHomes checks that every country has exactly one unit on each of
+// its home supply centers, and no units elsewhere.
+func (g *Game) validateHomes() error {
+	for _, c := range g.board.countries {
+		homes := 0
+		for p := range g.board.HomeCenters(c) {
+			homes += 1
+			u := g.units[p]
+			if u == nil {
+				return fmt.Errorf("%s home center %s is empty", c, p.name)
+			}
+			if u.country != c {
+				return fmt.Errorf("%s home center %s is occupied by %s", c, p.name, u.country)
+			}
+		}
+		if n := g.UnitCount(c); n != homes {
+			return fmt.Errorf("%s has %d units but %d home centers", c, n, homes)
+		}
+	}
+	return nil
+}
+
 // RemoveUnit vacates a space on the game board.
 func (g *Game) RemoveUnit(province *Province) {
 	delete(g.units, province)
@@ -1,12 +1,14 @@
 package diplo
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"iter"
 	"maps"
 	"slices"
 	"strings"
+	"sync"
 )
 
 func DefaultCoastParser(coast string) (string, bool) {
@@ -73,6 +75,7 @@ func (t Terrain) Supports(u Unit) bool {
 type Province struct {
 	name    string   // Full name
 	abbrs   []string // Unique abbreviations
+	aliases []string // Other names
 	terrain Terrain
 	coasts  []string // Named coasts, ignored if not coastal
 	center  bool     // Is supply center
@@ -96,6 +99,11 @@ func (p *Province) Abbreviations() []string {
 	return slices.Clone(p.abbrs)
 }
 
+// Aliases is the other names the province goes by, if any.
+func (p *Province) Aliases() []string {
+	return slices.Clone(p.aliases)
+}
+
 // Terrain controls which units can occupy the province.
 func (p *Province) Terrain() Terrain {
 	return p.terrain
@@ -201,6 +209,8 @@ type Board struct {
 	countries     []string
 	provinces     []*Province
 	connections   map[endpoints]*Connection
+	units         []*Occupancy
+	geometry      *Geometry
 	coastParser   func(string) (string, bool)
 	countryParser func(string) (string, bool)
 }
@@ -242,6 +252,12 @@ func (b *Board) Provinces() iter.Seq[*Province] {
 	return slices.Values(b.provinces)
 }
 
+// StartingUnits is the units placed at the start of a game on this board
+// (see [Builder.Units] and [NewStartingGame]).
+func (b *Board) StartingUnits() iter.Seq[*Occupancy] {
+	return slices.Values(b.units)
+}
+
 // Province gets the province on the board with the given name.
 // Returns nil if it doesn't exist.
 func (b *Board) Province(name string) *Province {
@@ -253,6 +269,16 @@ func (b *Board) Province(name string) *Province {
 	return nil
 }
 
+// abbreviated gets the province with the given abbreviation, or nil.
+func (b *Board) abbreviated(abbr string) *Province {
+	for _, p := range b.provinces {
+		if hasStringFold(p.abbrs, abbr) {
+			return p
+		}
+	}
+	return nil
+}
+
 func (b *Board) ParseProvince(id string) []*Province {
 	id = simplify(id)
 	var results []*Province
@@ -265,13 +291,99 @@ func (b *Board) ParseProvince(id string) []*Province {
 		return results
 	}
 	for _, p := range b.provinces {
-		if strings.HasPrefix(simplify(p.name), id) {
+		if strings.HasPrefix(simplify(p.name), id) || slices.ContainsFunc(p.aliases, func(a string) bool {
+			return strings.HasPrefix(simplify(a), id)
+		}) {
 			results = append(results, p)
 		}
 	}
 	return results
 }
 
+// ProvinceMatch is a province a name may refer to, with a score from 0 to 1
+// of how closely the name matches it.
+type ProvinceMatch struct {
+	Province *Province
+	Score    float64
+}
+
+// fuzzyThreshold is the lowest score of a match given by [Board.MatchProvince].
+const fuzzyThreshold = 0.6
+
+// MatchProvince finds the provinces a name, which may be misspelled, could
+// refer to, best first. Each province's score is its best against its name,
+// abbreviations, and aliases: 1 for an exact match, less for a prefix of a
+// name, and otherwise falling with the edit distance between them.
+//
+// Provinces that match too poorly are left out.
+func (b *Board) MatchProvince(name string) []ProvinceMatch {
+	id := simplify(name)
+	if id == "" {
+		return nil
+	}
+	var matches []ProvinceMatch
+	for _, p := range b.provinces {
+		best := 0.0
+		for _, abbr := range p.abbrs {
+			best = max(best, matchScore(id, strings.ToLower(abbr)))
+		}
+		for _, n := range append([]string{p.name}, p.aliases...) {
+			best = max(best, matchScore(id, simplify(n)))
+		}
+		if best >= fuzzyThreshold {
+			matches = append(matches, ProvinceMatch{p, best})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b ProvinceMatch) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	return matches
+}
+
+// matchScore scores how closely id matches s, both simplified.
+func matchScore(id, s string) float64 {
+	switch {
+	case id == s:
+		return 1
+	case strings.HasPrefix(s, id):
+		// At least as good as one typo in a name of the same length.
+		return 0.8 + 0.2*float64(len(id))/float64(len(s))
+	}
+	score := 1 - float64(editDistance(id, s))/float64(max(len(id), len(s)))
+	if len(id) < len(s) {
+		// A misspelled prefix, like "hollnd" for "hollandbight".
+		prefix := 1 - float64(editDistance(id, s[:len(id)]))/float64(len(id))
+		score = max(score, 0.9*prefix)
+	}
+	return score
+}
+
+// editDistance counts the insertions, deletions, substitutions, and swaps of
+// adjacent letters that change a into b.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
 // Centers is all supply centers on the board.
 func (b *Board) Centers() iter.Seq[*Province] {
 	return func(yield func(*Province) bool) {
@@ -407,3 +519,49 @@ func (b *Board) ParseCountry(country string) (string, bool) {
 		return DefaultCountryParser(country)
 	}
 }
+
+var (
+	boardsMu   sync.RWMutex
+	boards     = make(map[string]*Board) // by lowercase name
+	boardNames = make(map[*Board]string) // the first name each board was registered with
+)
+
+// RegisterBoard makes a board available by name, so that saved games can
+// refer to it (see [Game.MarshalJSON]). Names are case-insensitive. A board
+// may be registered under more than one name; games on it are saved with the
+// first.
+//
+// Boards are also registered with their variants (see [RegisterVariant]):
+// [StandardBoard] as "Standard" and [FleetRomeBoard] as "Fleet Rome".
+func RegisterBoard(name string, board *Board) error {
+	if name == "" || board == nil {
+		return errors.New("board and name required")
+	}
+	boardsMu.Lock()
+	defer boardsMu.Unlock()
+	key := strings.ToLower(name)
+	if _, ok := boards[key]; ok {
+		return fmt.Errorf("board %s already registered", name)
+	}
+	boards[key] = board
+	if _, ok := boardNames[board]; !ok {
+		boardNames[board] = name
+	}
+	return nil
+}
+
+// RegisteredBoard gets a board by the name it was registered with.
+func RegisteredBoard(name string) (*Board, bool) {
+	boardsMu.RLock()
+	defer boardsMu.RUnlock()
+	board, ok := boards[strings.ToLower(name)]
+	return board, ok
+}
+
+// boardName gets the first name a board was registered with, if any.
+func boardName(board *Board) (string, bool) {
+	boardsMu.RLock()
+	defer boardsMu.RUnlock()
+	name, ok := boardNames[board]
+	return name, ok
+}
@@ -0,0 +1,139 @@
+package diplo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "rome", 4},
+		{"rome", "rome", 0},
+		{"hollnd", "holland", 1},
+		{"lodnon", "london", 1},
+		{"bohemai", "bohemia", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := editDistance(tt.b, tt.a); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	if got := matchScore("holland", "holland"); got != 1 {
+		t.Errorf("exact match scores %v, want 1", got)
+	}
+	prefix := matchScore("hol", "holland")
+	if prefix < 0.8 || prefix >= 1 {
+		t.Errorf("prefix scores %v, want in [0.8, 1)", prefix)
+	}
+	if longer := matchScore("holla", "holland"); longer <= prefix {
+		t.Errorf("longer prefix scores %v, not above %v", longer, prefix)
+	}
+	typo := matchScore("hollnd", "holland")
+	if typo < fuzzyThreshold || typo >= 1 {
+		t.Errorf("typo scores %v, want in [%v, 1)", typo, fuzzyThreshold)
+	}
+	if got := matchScore("xyzzy", "holland"); got >= fuzzyThreshold {
+		t.Errorf("unrelated name scores %v, want below %v", got, fuzzyThreshold)
+	}
+}
+
+func TestMatchProvince(t *testing.T) {
+	tests := []struct {
+		name string
+		want string // best match, or empty for none
+	}{
+		{"Hollnd", "Holland"},
+		{"Nrth Sea", "North Sea"},
+		{"Edniburgh", "Edinburgh"},
+		{"Lodnon", "London"},
+		{"Bohemai", "Bohemia"},
+		{"Mscow", "Moscow"},
+		{"Tyrol", "Tyrolia"},
+		{"Saint Petersburg", "St. Petersburg"},
+		{"Gulf of Lyons", "Gulf of Lyon"},
+		{"Heligoland", "Helgoland Bight"},
+		{"xyzzy", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		matches := StandardBoard.MatchProvince(tt.name)
+		if tt.want == "" {
+			if len(matches) > 0 {
+				t.Errorf("MatchProvince(%q) = %s, want none", tt.name, matches[0].Province.name)
+			}
+			continue
+		}
+		if len(matches) == 0 || matches[0].Province.name != tt.want {
+			t.Errorf("MatchProvince(%q) best is not %s: %v", tt.name, tt.want, matches)
+			continue
+		}
+		for i, m := range matches {
+			if m.Score < fuzzyThreshold || i > 0 && m.Score > matches[i-1].Score {
+				t.Errorf("MatchProvince(%q) scores out of order: %v", tt.name, matches)
+				break
+			}
+		}
+	}
+	// Aliases match exactly.
+	if m := StandardBoard.MatchProvince("Saint Petersburg"); m[0].Score != 1 {
+		t.Errorf("alias scores %v, want 1", m[0].Score)
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	for _, on := range []bool{false, true} {
+		g := StandardGame()
+		g.SetSuggestions(on)
+		_, err := g.ParseOrder("F Lon - Nrth Sea", "England")
+		var unknown *UnknownProvinceError
+		if !errors.As(err, &unknown) {
+			t.Fatalf("suggestions %v: error %v, want an UnknownProvinceError", on, err)
+		}
+		if !on {
+			if len(unknown.Suggestions) > 0 {
+				t.Errorf("suggestions off: got %v", unknown.Suggestions)
+			}
+			continue
+		}
+		if len(unknown.Suggestions) == 0 || len(unknown.Suggestions) > maxSuggestions ||
+			unknown.Suggestions[0].Province.name != "North Sea" {
+			t.Errorf("suggestions on: got %v, want North Sea first", unknown.Suggestions)
+		}
+	}
+}
+
+func TestBoardNameFirstRegistered(t *testing.T) {
+	b := standardBuilder()
+	board, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The names are unique to the board, so that the test can run again.
+	first := fmt.Sprintf("Test Board %p", board)
+	for _, name := range []string{first, first + " Again", first + " Once More"} {
+		if err := RegisterBoard(name, board); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 10 {
+		if name, ok := boardName(board); !ok || name != first {
+			t.Fatalf("boardName = %q, %v, want %q", name, ok, first)
+		}
+	}
+	if got, ok := RegisteredBoard(strings.ToLower(first + " Again")); !ok || got != board {
+		t.Error("board not found by its second name")
+	}
+}
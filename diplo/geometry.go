@@ -0,0 +1,99 @@
+package diplo
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// Point is a position in a picture of the board, as x and y in pixels from
+// the top left.
+type Point [2]float64
+
+// Geometry is where a board's provinces are drawn, for pictures of games on
+// the board (see [Game.WriteSVG]).
+type Geometry struct {
+	// Width and Height are the size of the picture.
+	Width, Height float64
+	// Provinces is the outline and unit positions of each province, by its
+	// name or one of its abbreviations. Every province must be given.
+	Provinces map[string]ProvinceGeometry
+	// Impassable is outlines of land that is not part of any province, like
+	// Switzerland on the standard board.
+	Impassable [][]Point `json:",omitempty"`
+}
+
+// ProvinceGeometry is where a province is drawn.
+type ProvinceGeometry struct {
+	// Polygon is the outline of the province.
+	Polygon []Point
+	// Unit is where a unit in the province is drawn, along with its name and
+	// supply center.
+	Unit Point
+	// Coasts is where a Fleet is drawn on each of the province's named coasts.
+	// A coast left out is drawn at Unit.
+	Coasts map[string]Point `json:",omitempty"`
+}
+
+// resolveGeometry checks a geometry against the board, giving a copy with
+// provinces listed by name and coasts as the board names them.
+func (b *Board) resolveGeometry(g *Geometry) (*Geometry, error) {
+	if g.Width <= 0 || g.Height <= 0 {
+		return nil, errors.New("no size")
+	}
+	resolved := &Geometry{
+		Width:      g.Width,
+		Height:     g.Height,
+		Provinces:  make(map[string]ProvinceGeometry),
+		Impassable: g.Impassable,
+	}
+	for name, pg := range g.Provinces {
+		ps := b.ParseProvince(name)
+		if len(ps) != 1 {
+			return nil, fmt.Errorf("unknown province %s", name)
+		}
+		p := ps[0]
+		if _, ok := resolved.Provinces[p.name]; ok {
+			return nil, fmt.Errorf("%s given twice", p.name)
+		}
+		if len(pg.Polygon) < 3 {
+			return nil, fmt.Errorf("outline of %s has fewer than 3 points", p.name)
+		}
+		coasts := make(map[string]Point)
+		for c, pt := range pg.Coasts {
+			coast, ok := b.ParseCoast(c)
+			if !ok || !hasStringFold(p.coasts, coast) {
+				return nil, fmt.Errorf("no coast %s on %s", c, p.name)
+			}
+			coasts[coast] = pt
+		}
+		pg.Coasts = coasts
+		resolved.Provinces[p.name] = pg
+	}
+	for _, p := range b.provinces {
+		if _, ok := resolved.Provinces[p.name]; !ok {
+			return nil, fmt.Errorf("no outline for %s", p.name)
+		}
+	}
+	return resolved, nil
+}
+
+// unitPoint is where a unit is drawn in a province, on a coast if given.
+func (g *Geometry) unitPoint(p *Province, coast string) Point {
+	pg := g.Provinces[p.name]
+	if pt, ok := pg.Coasts[coast]; ok {
+		return pt
+	}
+	return pg.Unit
+}
+
+// Geometry gets where the board's provinces are drawn, or nil if the board
+// has no geometry (see [Builder.Geometry]).
+func (b *Board) Geometry() *Geometry {
+	if b.geometry == nil {
+		return nil
+	}
+	g := *b.geometry
+	g.Provinces = maps.Clone(g.Provinces)
+	return &g
+}
@@ -0,0 +1,355 @@
+package diplo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// History is a tree of game states. Each state but the first follows from
+// its parent by the orders given in the parent's phase.
+//
+// A state may have several continuations: the first is its main line and
+// the rest are variations, like the alternative lines of a chess game record.
+type History struct {
+	root *Position
+}
+
+// Position is a game state within a [History].
+type Position struct {
+	game       *Game
+	parent     *Position
+	orders     []OrderRecord
+	variations []*Position
+}
+
+// OrderRecord is an order given in a phase, with its outcome.
+type OrderRecord struct {
+	Country string
+	Order   Order
+	Outcome Outcome
+}
+
+// NewHistory starts a history from a game state.
+func NewHistory(game *Game) *History {
+	return &History{root: &Position{game: game}}
+}
+
+// Root is the state the history starts from.
+func (h *History) Root() *Position {
+	return h.root
+}
+
+// MainLine is the root and every state following it on the main line.
+func (h *History) MainLine() []*Position {
+	return h.root.Line()
+}
+
+// Find gets the state on the main line with the given phase label
+// (see [Game.Label]), or nil if there is none.
+func (h *History) Find(label string) *Position {
+	return h.root.Find(label)
+}
+
+// Game is the game state.
+func (p *Position) Game() *Game {
+	return p.game
+}
+
+// Label is the label of the game's phase, e.g. "F1903R".
+func (p *Position) Label() string {
+	return p.game.Label()
+}
+
+// Parent is the state this one followed from, or nil for the root.
+func (p *Position) Parent() *Position {
+	return p.parent
+}
+
+// Orders is the orders given in the parent's phase, with their outcomes,
+// that led to this state, in the order they were added to the arena.
+func (p *Position) Orders() []OrderRecord {
+	return slices.Clone(p.orders)
+}
+
+// Next is the state following this one on its main line, or nil.
+func (p *Position) Next() *Position {
+	if len(p.variations) == 0 {
+		return nil
+	}
+	return p.variations[0]
+}
+
+// Variations is every state following this one, the main line first.
+func (p *Position) Variations() []*Position {
+	return slices.Clone(p.variations)
+}
+
+// MainLine tells whether the state is on the main line of the history.
+func (p *Position) MainLine() bool {
+	for ; p.parent != nil; p = p.parent {
+		if p.parent.variations[0] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// Line is the states from the root to this one, followed by this one's main
+// line.
+func (p *Position) Line() []*Position {
+	var line []*Position
+	for a := p.parent; a != nil; a = a.parent {
+		line = append(line, a)
+	}
+	slices.Reverse(line)
+	for n := p; n != nil; n = n.Next() {
+		line = append(line, n)
+	}
+	return line
+}
+
+// Find gets the state with the given phase label (see [Game.Label]) in the
+// line of this state (see [Position.Line]), or nil if there is none.
+func (p *Position) Find(label string) *Position {
+	year, phase, err := ParseLabel(label)
+	if err != nil {
+		return nil
+	}
+	for _, n := range p.Line() {
+		if n.game.year == year && n.game.phase == phase {
+			return n
+		}
+	}
+	return nil
+}
+
+// Play adjudicates an arena of this state's game and records the resulting
+// state. It becomes the main line if the state had no continuation yet, and
+// a variation otherwise.
+//
+// Units without orders are given default orders, as with [Arena.Go].
+func (p *Position) Play(a *Arena) (*Position, error) {
+	if a.game != p.game {
+		return nil, errors.New("arena is not for this game state")
+	}
+	next := &Position{game: a.Go(), parent: p}
+	// Orders are kept in the order they were added, since the outcomes of
+	// builds depend on it.
+	for _, g := range a.given {
+		next.orders = append(next.orders, OrderRecord{g.country, g.order, a.countryOrders[g.country][g.order]})
+	}
+	p.variations = append(p.variations, next)
+	return next, nil
+}
+
+// province is the province of the ordered unit, or of the build.
+func (o Order) province() *Province {
+	if o.Unit != nil {
+		return o.Unit
+	}
+	return o.Target
+}
+
+// Promote makes this state the main line of its parent, and the parent the
+// main line of its own parent, and so on, so that it is on the main line
+// of the history.
+func (p *Position) Promote() {
+	for ; p.parent != nil; p = p.parent {
+		vs := p.parent.variations
+		i := slices.Index(vs, p)
+		copy(vs[1:i+1], vs[:i])
+		vs[0] = p
+	}
+}
+
+// Remove takes this state, and every state following it, out of the history.
+// The root cannot be removed.
+func (p *Position) Remove() {
+	if p.parent == nil {
+		return
+	}
+	p.parent.variations = slices.DeleteFunc(p.parent.variations, func(v *Position) bool {
+		return v == p
+	})
+	p.parent = nil
+}
+
+type jsonOrder struct {
+	Country   string
+	Unit      string `json:",omitempty"`
+	Recipient string `json:",omitempty"`
+	Target    string `json:",omitempty"`
+	Coast     string `json:",omitempty"`
+	Convoy    bool   `json:",omitempty"`
+	ViaConvoy bool   `json:",omitempty"`
+	Build     string `json:",omitempty"`
+	Waive     bool   `json:",omitempty"`
+	Outcome   string
+}
+
+type jsonPosition struct {
+	Game       *Game
+	Orders     []jsonOrder     `json:",omitempty"`
+	Variations []*jsonPosition `json:",omitempty"`
+}
+
+// MarshalJSON writes the tree of game states as JSON. Each state is written
+// as with [Game.MarshalJSON], with the orders that led to it and the states
+// following it.
+func (h *History) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.root.toJSON())
+}
+
+func (p *Position) toJSON() *jsonPosition {
+	jp := &jsonPosition{Game: p.game}
+	name := func(p *Province) string {
+		if p == nil {
+			return ""
+		}
+		return p.name
+	}
+	for _, r := range p.orders {
+		jo := jsonOrder{
+			Country:   r.Country,
+			Unit:      name(r.Order.Unit),
+			Recipient: name(r.Order.Recipient),
+			Target:    name(r.Order.Target),
+			Coast:     r.Order.TargetCoast,
+			Convoy:    r.Order.Convoy,
+			ViaConvoy: r.Order.ViaConvoy,
+			Waive:     r.Order.Waive,
+			Outcome:   r.Outcome.String(),
+		}
+		if r.Order.Kind() == Build {
+			jo.Build = r.Order.Build.String()
+		}
+		jp.Orders = append(jp.Orders, jo)
+	}
+	for _, v := range p.variations {
+		jp.Variations = append(jp.Variations, v.toJSON())
+	}
+	return jp
+}
+
+// UnmarshalJSON reads a tree of game states written by
+// [History.MarshalJSON], replacing the history.
+//
+// If the history already has a root state, every state is read onto its
+// board. Otherwise, the board is found as with [Game.UnmarshalJSON].
+func (h *History) UnmarshalJSON(data []byte) error {
+	var board *Board
+	if h.root != nil {
+		board = h.root.game.board
+	}
+	jp := &jsonPosition{Game: &Game{board: board}}
+	if err := jp.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	root, err := jp.fromJSON(nil)
+	if err != nil {
+		return err
+	}
+	h.root = root
+	return nil
+}
+
+// UnmarshalJSON reads a state with its game on the board of the game
+// already set, if any.
+func (jp *jsonPosition) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Game       json.RawMessage
+		Orders     []jsonOrder
+		Variations []json.RawMessage
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	if raw.Game == nil {
+		return errors.New("state has no game")
+	}
+	if err := jp.Game.UnmarshalJSON(raw.Game); err != nil {
+		return err
+	}
+	jp.Orders = raw.Orders
+	for _, v := range raw.Variations {
+		jv := &jsonPosition{Game: &Game{board: jp.Game.board}}
+		if err := jv.UnmarshalJSON(v); err != nil {
+			return err
+		}
+		jp.Variations = append(jp.Variations, jv)
+	}
+	return nil
+}
+
+func (jp *jsonPosition) fromJSON(parent *Position) (*Position, error) {
+	p := &Position{game: jp.Game, parent: parent}
+	if parent == nil && len(jp.Orders) > 0 {
+		return nil, errors.New("first state has orders")
+	}
+	for _, jo := range jp.Orders {
+		r, err := parent.game.fromJSONOrder(jo)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", parent.Label(), err)
+		}
+		p.orders = append(p.orders, r)
+	}
+	for _, jv := range jp.Variations {
+		v, err := jv.fromJSON(p)
+		if err != nil {
+			return nil, err
+		}
+		p.variations = append(p.variations, v)
+	}
+	return p, nil
+}
+
+func (g *Game) fromJSONOrder(jo jsonOrder) (OrderRecord, error) {
+	r := OrderRecord{Country: jo.Country}
+	if !slices.Contains(g.board.countries, jo.Country) {
+		return r, fmt.Errorf("board does not have country '%s'", jo.Country)
+	}
+	province := func(name string) (*Province, error) {
+		if name == "" {
+			return nil, nil
+		}
+		if p := g.board.Province(name); p != nil {
+			return p, nil
+		}
+		return nil, fmt.Errorf("no province %s", name)
+	}
+	var err error
+	if r.Order.Unit, err = province(jo.Unit); err != nil {
+		return r, err
+	}
+	if r.Order.Recipient, err = province(jo.Recipient); err != nil {
+		return r, err
+	}
+	if r.Order.Target, err = province(jo.Target); err != nil {
+		return r, err
+	}
+	r.Order.TargetCoast = jo.Coast
+	r.Order.Convoy = jo.Convoy
+	r.Order.ViaConvoy = jo.ViaConvoy
+	r.Order.Waive = jo.Waive
+	if jo.Build != "" {
+		unit, ok := ParseUnit(jo.Build)
+		if !ok {
+			return r, fmt.Errorf("unknown unit type %s", jo.Build)
+		}
+		r.Order.Build = unit
+	}
+	if r.Order.Kind() == InvalidOrder {
+		return r, errors.New("malformed order")
+	}
+	outcome, ok := ParseOutcome(jo.Outcome)
+	if !ok {
+		return r, fmt.Errorf("unknown outcome %s", jo.Outcome)
+	}
+	r.Outcome = outcome
+	return r, nil
+}
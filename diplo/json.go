@@ -0,0 +1,187 @@
+package diplo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type jsonUnit struct {
+	Country  string
+	Unit     string
+	Province string
+	Coast    string `json:",omitempty"`
+	// Attacker is where a dislodged unit's attacker came from,
+	// empty if it was convoyed.
+	Attacker string `json:",omitempty"`
+}
+
+type jsonGame struct {
+	Board     string `json:",omitempty"`
+	Year      int
+	Phase     string
+	Rules     RuleSet
+	Units     []jsonUnit
+	Centers   map[string]string
+	Dislodged []jsonUnit `json:",omitempty"`
+	Contested []string   `json:",omitempty"`
+}
+
+// MarshalJSON writes the game state as JSON: the year and phase, the rules,
+// the units, who controls each supply center, and in retreat phases, the
+// dislodged units and contested provinces.
+//
+// Provinces are written by name. The board itself is not written, only the
+// name it was registered with (see [RegisterBoard]), if any.
+func (g *Game) MarshalJSON() ([]byte, error) {
+	jg := jsonGame{
+		Year:    g.year,
+		Phase:   g.phase.String(),
+		Rules:   g.rules,
+		Units:   []jsonUnit{},
+		Centers: make(map[string]string),
+	}
+	jg.Board, _ = boardName(g.board)
+	for _, p := range g.board.provinces {
+		if u := g.units[p]; u != nil {
+			jg.Units = append(jg.Units, toJSONUnit(u))
+		}
+		if c := g.centers[p]; c != "" {
+			jg.Centers[p.name] = c
+		}
+		if u := g.dislodged[p]; u != nil {
+			ju := toJSONUnit(u)
+			if a := g.attackers[u]; a != nil {
+				ju.Attacker = a.name
+			}
+			jg.Dislodged = append(jg.Dislodged, ju)
+		}
+		if g.contests[p] {
+			jg.Contested = append(jg.Contested, p.name)
+		}
+	}
+	return json.Marshal(jg)
+}
+
+func toJSONUnit(u *Occupancy) jsonUnit {
+	return jsonUnit{
+		Country:  u.country,
+		Unit:     u.unit.String(),
+		Province: u.province.name,
+		Coast:    u.coast,
+	}
+}
+
+// UnmarshalJSON reads a game state written by [Game.MarshalJSON],
+// replacing the game's state.
+//
+// If the game already has a board (as made by [NewGame]), the state is read
+// onto that board. Otherwise, the board is found by the name recorded in the
+// JSON, which must have been registered with [RegisterBoard].
+//
+// The state is validated against the board: every field must be known, every
+// province, coast, country, and supply center must exist, units may not share
+// a province, and dislodged units and contested provinces may only be given
+// in retreat phases.
+func (g *Game) UnmarshalJSON(data []byte) error {
+	var jg jsonGame
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&jg); err != nil {
+		return err
+	}
+	board := g.board
+	if board == nil {
+		if jg.Board == "" {
+			return errors.New("no board given")
+		}
+		var ok bool
+		if board, ok = RegisteredBoard(jg.Board); !ok {
+			return fmt.Errorf("no board registered as %s", jg.Board)
+		}
+	}
+	if jg.Year < StartYear {
+		return fmt.Errorf("year %d is before %d", jg.Year, StartYear)
+	}
+	phase, ok := ParsePhase(jg.Phase)
+	if !ok {
+		return fmt.Errorf("unknown phase %s", jg.Phase)
+	}
+	next := NewGame(board)
+	next.year = jg.Year
+	next.phase = phase
+	next.rules = jg.Rules
+	for _, ju := range jg.Units {
+		p, u, err := next.fromJSONUnit(ju)
+		if err != nil {
+			return err
+		}
+		if next.units[p] != nil {
+			return fmt.Errorf("two units in %s", p.name)
+		}
+		next.units[p] = u
+	}
+	for p := range next.centers {
+		next.centers[p] = ""
+	}
+	for name, country := range jg.Centers {
+		p := board.Province(name)
+		if err := board.validCenter(p); err != nil {
+			return fmt.Errorf("center %s: %w", name, err)
+		}
+		if country != "" && !slices.Contains(board.countries, country) {
+			return fmt.Errorf("board does not have country '%s'", country)
+		}
+		next.centers[p] = country
+	}
+	if !phase.Retreat() && (len(jg.Dislodged) > 0 || len(jg.Contested) > 0) {
+		return errors.New("dislodged units and contested provinces are only allowed in retreat phases")
+	}
+	for _, ju := range jg.Dislodged {
+		p, u, err := next.fromJSONUnit(ju)
+		if err != nil {
+			return err
+		}
+		if next.dislodged[p] != nil {
+			return fmt.Errorf("two dislodged units in %s", p.name)
+		}
+		var from *Province
+		if ju.Attacker != "" {
+			if from = board.Province(ju.Attacker); from == nil {
+				return fmt.Errorf("no province %s", ju.Attacker)
+			}
+		}
+		next.dislodged[p] = u
+		next.attackers[u] = from
+	}
+	for _, name := range jg.Contested {
+		p := board.Province(name)
+		if p == nil {
+			return fmt.Errorf("no province %s", name)
+		}
+		next.contests[p] = true
+	}
+	*g = *next
+	return nil
+}
+
+func (g *Game) fromJSONUnit(ju jsonUnit) (*Province, *Occupancy, error) {
+	p := g.board.Province(ju.Province)
+	if p == nil {
+		return nil, nil, fmt.Errorf("no province %s", ju.Province)
+	}
+	unit, ok := ParseUnit(ju.Unit)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown unit type %s", ju.Unit)
+	}
+	if !slices.Contains(g.board.countries, ju.Country) {
+		return nil, nil, fmt.Errorf("board does not have country '%s'", ju.Country)
+	}
+	u, err := g.validSetUnit(p, ju.Coast, unit, ju.Country)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unit in %s: %w", p.name, err)
+	}
+	return p, u, nil
+}
@@ -0,0 +1,92 @@
+package diplo
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		notation string
+	}{
+		{"spring", "S1901M F:ABur,FBre;G:AMun,ABer;SC:Par=F,Bre=F,Mun=G"},
+		{"split coast", "F1902M R:FStP/NC,ASev;SC:StP=R,Sev=R"},
+		{"retreat", "F1901R E:FNTH;T:ABul,FBla;D:FBul/EC=E<Con"},
+		{"contested", "S1903R I:AMar;SC:Mar=F;D:AMar=F<Pie;X:Bur"},
+		{"winter", "W1901A E:FNTH,AYor;SC:Lon=E,Edi=E,Lvp=E,Nwy=E"},
+		{"rules", "S1901M F:APar;SC:;RULES:Paradox=2000,DisbandOnNMR,VictoryCenters=20"},
+	}
+	for _, tt := range tests {
+		g, err := ParseNotation(StandardBoard, tt.notation)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		data, err := json.Marshal(g)
+		if err != nil {
+			t.Fatalf("%s: Marshal: %v", tt.name, err)
+		}
+		var read Game
+		if err := json.Unmarshal(data, &read); err != nil {
+			t.Fatalf("%s: Unmarshal(%s): %v", tt.name, data, err)
+		}
+		if got, want := read.Notation(), g.Notation(); got != want {
+			t.Errorf("%s: read back as %q, want %q", tt.name, got, want)
+		}
+		again, err := json.Marshal(&read)
+		if err != nil {
+			t.Fatalf("%s: Marshal: %v", tt.name, err)
+		}
+		if !bytes.Equal(again, data) {
+			t.Errorf("%s: written again as\n%s\nwant\n%s", tt.name, again, data)
+		}
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		err  string
+	}{
+		{"unknown field",
+			`{"Board":"Standard","Year":1901,"Phase":"Spring","Units":[],"Centers":{},"Winner":"France"}`,
+			`unknown field "Winner"`},
+		{"unknown board",
+			`{"Board":"Atlantis","Year":1901,"Phase":"Spring","Units":[],"Centers":{}}`,
+			"no board registered as Atlantis"},
+		{"no board",
+			`{"Year":1901,"Phase":"Spring","Units":[],"Centers":{}}`,
+			"no board given"},
+		{"army at sea",
+			`{"Board":"Standard","Year":1901,"Phase":"Spring","Units":[{"Country":"England","Unit":"Army","Province":"North Sea"}],"Centers":{}}`,
+			"unit in North Sea"},
+		{"fleet inland",
+			`{"Board":"Standard","Year":1901,"Phase":"Spring","Units":[{"Country":"Germany","Unit":"Fleet","Province":"Munich"}],"Centers":{}}`,
+			"unit in Munich"},
+		{"unknown country",
+			`{"Board":"Standard","Year":1901,"Phase":"Spring","Units":[{"Country":"Prussia","Unit":"Army","Province":"Berlin"}],"Centers":{}}`,
+			"country 'Prussia'"},
+		{"unknown province",
+			`{"Board":"Standard","Year":1901,"Phase":"Spring","Units":[{"Country":"Germany","Unit":"Army","Province":"Hamburg"}],"Centers":{}}`,
+			"no province Hamburg"},
+		{"not a center",
+			`{"Board":"Standard","Year":1901,"Phase":"Spring","Units":[],"Centers":{"Burgundy":"France"}}`,
+			"center Burgundy"},
+		{"dislodged in spring",
+			`{"Board":"Standard","Year":1901,"Phase":"Spring","Units":[],"Centers":{},"Dislodged":[{"Country":"France","Unit":"Army","Province":"Paris"}]}`,
+			"only allowed in retreat phases"},
+		{"unknown phase",
+			`{"Board":"Standard","Year":1901,"Phase":"Monsoon","Units":[],"Centers":{}}`,
+			"unknown phase Monsoon"},
+	}
+	for _, tt := range tests {
+		var g Game
+		err := json.Unmarshal([]byte(tt.json), &g)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: Unmarshal error %v, want one containing %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package diplo
+
+import "slices"
+
+// LegalOrders gets every order a unit could be given in the current phase
+// without it being illegal, in board order.
+//
+// In move phases, these are holding; moving to each destination (see
+// [Game.Destinations]), to each coast a Fleet can reach, and by convoy where
+// a route exists; supporting each unit that could hold or move where this
+// unit can reach; and, for Fleets, convoying Armies along routes through the
+// Fleet's province. Supports are given without coasts.
+//
+// In retreat phases, the unit must be dislodged (see [Game.DislodgedUnit]),
+// and its orders are disbanding and each retreat open to it. In Winter, the
+// unit's only order is disbanding, if its country must disband units; see
+// [Game.LegalAdjustments] for builds.
+//
+// Orders that are legal may still fail once adjudicated.
+func (g *Game) LegalOrders(unit *Occupancy) []Order {
+	if unit == nil {
+		return nil
+	}
+	a := g.Arena()
+	var orders []Order
+	add := func(order Order) {
+		if a.check(unit.country, order) == OutcomeSuccess {
+			orders = append(orders, order)
+		}
+	}
+	switch {
+	case g.phase.Move():
+		if g.Unit(unit.province) != unit {
+			return nil
+		}
+		add(OrderHoldDisband(unit.province))
+		g.legalMoves(unit, add)
+		g.legalSupports(unit, add)
+		g.legalConvoys(unit, add)
+	case g.phase.Retreat():
+		if g.DislodgedUnit(unit.province) != unit {
+			return nil
+		}
+		add(OrderHoldDisband(unit.province))
+		for _, p := range g.board.provinces {
+			if g.HasNeighbor(unit, p) {
+				for _, coast := range unitCoasts(unit.unit, p) {
+					add(OrderMoveRetreat(unit.province, p, coast))
+				}
+			}
+		}
+	case g.phase == Winter:
+		if g.Unit(unit.province) == unit {
+			add(OrderHoldDisband(unit.province))
+		}
+	}
+	return orders
+}
+
+// LegalAdjustments gets every order a country could give in Winter without
+// it being illegal: a build of each kind of unit, on each coast for Fleets,
+// in each center it may build in, and waiving, if it has builds; or
+// disbanding each of its units, if it must disband. It gets nothing outside
+// of Winter.
+func (g *Game) LegalAdjustments(country string) []Order {
+	if g.phase != Winter {
+		return nil
+	}
+	a := g.Arena()
+	var orders []Order
+	add := func(order Order) {
+		if a.check(country, order) == OutcomeSuccess {
+			orders = append(orders, order)
+		}
+	}
+	for _, p := range g.board.provinces {
+		if u := g.Unit(p); u != nil {
+			add(OrderHoldDisband(p))
+			continue
+		}
+		if !p.center {
+			continue
+		}
+		for _, unit := range []Unit{Army, Fleet} {
+			for _, coast := range unitCoasts(unit, p) {
+				order := OrderBuild(p, unit)
+				order.TargetCoast = coast
+				add(order)
+			}
+		}
+	}
+	add(OrderWaive())
+	return orders
+}
+
+// legalMoves gives a unit's move orders to add, including by convoy.
+func (g *Game) legalMoves(unit *Occupancy, add func(Order)) {
+	destinations := make(map[*Province]bool)
+	for p := range g.Destinations(unit) {
+		destinations[p] = true
+	}
+	for _, p := range g.board.provinces {
+		if !destinations[p] {
+			continue
+		}
+		for _, coast := range unitCoasts(unit.unit, p) {
+			add(OrderMoveRetreat(unit.province, p, coast))
+		}
+		// Adjacent moves are only worth convoying if a route exists.
+		if unit.unit == Army && g.HasNeighbor(unit, p) && len(g.ConvoyChains(unit.province, p)) > 0 {
+			add(OrderMoveViaConvoy(unit.province, p))
+		}
+	}
+}
+
+// legalSupports gives a unit's support orders to add, for each other unit
+// holding or moving to a province the unit can reach.
+func (g *Game) legalSupports(unit *Occupancy, add func(Order)) {
+	for _, r := range g.board.provinces {
+		recipient := g.Unit(r)
+		if recipient == nil || recipient == unit {
+			continue
+		}
+		add(OrderSupportHold(unit.province, r))
+		destinations := make(map[*Province]bool)
+		for p := range g.Destinations(recipient) {
+			destinations[p] = true
+		}
+		for _, p := range g.board.provinces {
+			if destinations[p] {
+				add(OrderSupportMove(unit.province, r, p, ""))
+			}
+		}
+	}
+}
+
+// legalConvoys gives a Fleet's convoy orders to add, for each Army with a
+// convoy route through the Fleet's province.
+func (g *Game) legalConvoys(unit *Occupancy, add func(Order)) {
+	if unit.unit != Fleet || !g.convoyable(unit.province) {
+		return
+	}
+	for _, r := range g.board.provinces {
+		army := g.Unit(r)
+		if army == nil || army.unit != Army || r.terrain != Coastal {
+			continue
+		}
+		for _, p := range g.board.provinces {
+			if p == r || p.terrain != Coastal {
+				continue
+			}
+			if slices.ContainsFunc(g.ConvoyChains(r, p), func(chain []*Province) bool {
+				return slices.Contains(chain, unit.province)
+			}) {
+				add(OrderConvoy(unit.province, r, p))
+			}
+		}
+	}
+}
+
+// unitCoasts is the coasts a unit could be ordered to in a province: each
+// named coast for a Fleet, or none.
+func unitCoasts(unit Unit, p *Province) []string {
+	if unit == Fleet && len(p.Coasts()) > 0 {
+		return p.Coasts()
+	}
+	return []string{""}
+}
+
+// check gets whether an order could be added for a country, regardless of
+// the outcomes of other orders.
+func (a *Arena) check(country string, order Order) Outcome {
+	var o Outcome
+	switch {
+	case a.game.phase.Move():
+		_, o = a.doMovePhase(country, order)
+	case a.game.phase.Retreat():
+		_, o = a.doRetreatPhase(country, order)
+	case a.game.phase == Winter:
+		_, o = a.doBuildPhase(country, order)
+	}
+	return o
+}
@@ -0,0 +1,124 @@
+package diplo
+
+import (
+	"slices"
+	"testing"
+)
+
+func formatOrders(g *Game, orders []Order) []string {
+	texts := make([]string, len(orders))
+	for i, o := range orders {
+		texts[i] = o.Format(g, StyleWebDip)
+	}
+	return texts
+}
+
+func TestLegalOrders(t *testing.T) {
+	tests := []struct {
+		name     string
+		notation string
+		province string
+		want     []string
+	}{
+		{"start", "", "London", []string{
+			"F Lon H",
+			"F Lon - ENG", "F Lon - NTH", "F Lon - Wal", "F Lon - Yor",
+			"F Lon S F Bre - ENG", "F Lon S F Edi - NTH", "F Lon S F Edi - Yor",
+			"F Lon S A Lvp - Wal", "F Lon S A Lvp - Yor",
+		}},
+		{"split coast", "S1901M F:FMAO;SC:", "Mid-Atlantic Ocean", []string{
+			"F MAO H",
+			"F MAO - Bre", "F MAO - ENG", "F MAO - Gas", "F MAO - IRI", "F MAO - NAf",
+			"F MAO - NAO", "F MAO - Por", "F MAO - Spa(NC)", "F MAO - Spa(SC)", "F MAO - WES",
+		}},
+		{"convoy", "S1901M E:ALon,FNTH,FENG;SC:", "London", []string{
+			"A Lon H",
+			"A Lon - Bel", "A Lon - Bre", "A Lon - Den", "A Lon - Edi", "A Lon - Hol",
+			"A Lon - Nwy", "A Lon - Pic", "A Lon - Wal", "A Lon - Wal via convoy",
+			"A Lon - Yor", "A Lon - Yor via convoy",
+			"A Lon S F ENG - Wal", "A Lon S F NTH - Yor",
+		}},
+		// Bur is where the attacker came from, Sil was contested, and Boh is occupied.
+		{"retreat", "S1902R F:AMun;A:ABoh;D:AMun=G<Bur;X:Sil", "Munich", []string{
+			"A Mun D",
+			"A Mun - Ber", "A Mun - Kie", "A Mun - Ruh", "A Mun - Tyr",
+		}},
+	}
+	for _, tt := range tests {
+		g := StandardGame()
+		if tt.notation != "" {
+			var err error
+			if g, err = ParseNotation(StandardBoard, tt.notation); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+		}
+		unit := g.orderedUnit(StandardBoard.Province(tt.province))
+		if got := formatOrders(g, g.LegalOrders(unit)); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: LegalOrders =\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLegalOrdersNotOrderable(t *testing.T) {
+	g, err := ParseNotation(StandardBoard, "S1902R F:AMun;A:ABoh;D:AMun=G<Bur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Units that were not dislodged have no orders in retreat phases.
+	if got := g.LegalOrders(g.Unit(StandardBoard.Province("Bohemia"))); got != nil {
+		t.Errorf("LegalOrders(A Boh) = %q, want none", formatOrders(g, got))
+	}
+	if got := g.LegalOrders(nil); got != nil {
+		t.Errorf("LegalOrders(nil) = %q, want none", formatOrders(g, got))
+	}
+	if got := g.LegalAdjustments("Germany"); got != nil {
+		t.Errorf("LegalAdjustments outside Winter = %q, want none", formatOrders(g, got))
+	}
+}
+
+func TestLegalAdjustments(t *testing.T) {
+	// England and Russia have builds, with Edinburgh and Moscow occupied;
+	// Germany must disband; France has nothing to do.
+	g, err := ParseNotation(StandardBoard,
+		"W1901A E:FEdi,AYor;G:ABer,AMun;R:AMos;SC:Lon=E,Edi=E,Lvp=E,Nwy=E,StP=R,Mos=R,Swe=R,Ber=G")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		country string
+		want    []string
+	}{
+		{"England", []string{"Build A Lvp", "Build F Lvp", "Build A Lon", "Build F Lon", "Waive"}},
+		{"Russia", []string{"Build A StP", "Build F StP(NC)", "Build F StP(SC)", "Waive"}},
+		{"Germany", []string{"A Ber D", "A Mun D"}},
+		{"France", nil},
+	}
+	for _, tt := range tests {
+		if got := formatOrders(g, g.LegalAdjustments(tt.country)); !slices.Equal(got, tt.want) {
+			t.Errorf("LegalAdjustments(%s) =\n%q\nwant\n%q", tt.country, got, tt.want)
+		}
+	}
+	// Units only get disbands, and only when their country must disband.
+	if got := formatOrders(g, g.LegalOrders(g.Unit(StandardBoard.Province("Munich")))); !slices.Equal(got, []string{"A Mun D"}) {
+		t.Errorf("LegalOrders(A Mun) = %q, want disbanding", got)
+	}
+	if got := g.LegalOrders(g.Unit(StandardBoard.Province("Yorkshire"))); got != nil {
+		t.Errorf("LegalOrders(A Yor) = %q, want none", formatOrders(g, got))
+	}
+}
+
+func TestConvoyChainsAdjacent(t *testing.T) {
+	g, err := ParseNotation(StandardBoard, "S1901M E:ALon,FENG;SC:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lon, wal := StandardBoard.Province("London"), StandardBoard.Province("Wales")
+	eng := StandardBoard.Province("English Channel")
+	// The direct connection is not a chain, and does not stop the search.
+	for range 10 {
+		chains := g.ConvoyChains(lon, wal)
+		if len(chains) != 1 || !slices.Equal(chains[0], []*Province{eng}) {
+			t.Fatalf("ConvoyChains(Lon, Wal) = %v, want [[ENG]]", chains)
+		}
+	}
+}
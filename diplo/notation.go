@@ -0,0 +1,395 @@
+package diplo
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Sections of the notation that are not countries.
+const (
+	centersKey   = "SC"
+	dislodgedKey = "D"
+	contestedKey = "X"
+	rulesKey     = "RULES"
+)
+
+var phaseNotations = [...]string{
+	Spring:         "SM",
+	SpringRetreats: "SR",
+	Fall:           "FM",
+	FallRetreats:   "FR",
+	Winter:         "WA",
+}
+
+// Label names the phase of the game, with the season letter (S, F, or W),
+// the year, and the phase letter (M for moves, R for retreats, A for
+// adjustments), e.g. "F1903R".
+func (g *Game) Label() string {
+	phase := phaseNotations[g.phase]
+	return fmt.Sprintf("%c%d%c", phase[0], g.year, phase[1])
+}
+
+// ParseLabel interprets a phase label, as given by [Game.Label].
+// Case is ignored.
+func ParseLabel(label string) (year int, phase Phase, err error) {
+	if len(label) < 3 {
+		return 0, 0, fmt.Errorf("bad phase %s", label)
+	}
+	code := strings.ToUpper(label[:1] + label[len(label)-1:])
+	i := slices.Index(phaseNotations[:], code)
+	if i < 0 {
+		return 0, 0, fmt.Errorf("bad phase %s", label)
+	}
+	year, err = strconv.Atoi(label[1 : len(label)-1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad year in %s", label)
+	}
+	if year < StartYear {
+		return 0, 0, fmt.Errorf("year %d is before %d", year, StartYear)
+	}
+	return year, Phase(i), nil
+}
+
+// Notation writes the game state on a single line, for example:
+//
+//	S1901M A:ABud,AVie,FTri;E:FEdi,ALvp,FLon;...;R:...,FStP/SC;...;SC:Bud=A,Tri=A,...
+//
+// It begins with the phase label (see [Game.Label]). Then come sections
+// separated by semicolons:
+//
+//   - For each country with units, its code (the shortest prefix of the
+//     country names that tells them apart, or else its number on the
+//     board, counting from 1), then its units, each a unit letter and a
+//     province abbreviation, with "/" and the coast for fleets on split
+//     coasts.
+//   - SC, then each controlled supply center with "=" and the code of the
+//     country controlling it. Supply centers not given are uncontrolled.
+//   - In retreat phases, D, then each dislodged unit with "=" and its
+//     country code, then "<" and the province it was dislodged from, unless
+//     it was dislodged by a convoyed army.
+//   - In retreat phases, X, then each contested province.
+//   - RULES, then the rules that differ from the standard rules (see
+//     [RuleSet]), e.g. "Paradox=1982,BuildAnywhere,VictoryCenters=20".
+//
+// Provinces are written with their first abbreviation. Empty sections are
+// left out. [ParseNotation] reads the notation back.
+func (g *Game) Notation() string {
+	codes := countryCodes(g.board.countries)
+	var sb strings.Builder
+	sb.WriteString(g.Label())
+	var sections []string
+	for _, c := range g.board.countries {
+		var units []string
+		for _, p := range g.board.provinces {
+			if u := g.units[p]; u != nil && u.country == c {
+				units = append(units, unitNotation(u))
+			}
+		}
+		if len(units) > 0 {
+			sections = append(sections, codes[c]+":"+strings.Join(units, ","))
+		}
+	}
+	var centers, dislodged, contested []string
+	for _, p := range g.board.provinces {
+		if c := g.centers[p]; c != "" {
+			centers = append(centers, p.abbrs[0]+"="+codes[c])
+		}
+		if u := g.dislodged[p]; u != nil {
+			s := unitNotation(u) + "=" + codes[u.country]
+			if a := g.attackers[u]; a != nil {
+				s += "<" + a.abbrs[0]
+			}
+			dislodged = append(dislodged, s)
+		}
+		if g.contests[p] {
+			contested = append(contested, p.abbrs[0])
+		}
+	}
+	rules := rulesNotation(g.rules)
+	for _, s := range []struct {
+		key   string
+		items []string
+	}{
+		{centersKey, centers},
+		{dislodgedKey, dislodged},
+		{contestedKey, contested},
+		{rulesKey, rules},
+	} {
+		if len(s.items) > 0 {
+			sections = append(sections, s.key+":"+strings.Join(s.items, ","))
+		}
+	}
+	if len(sections) > 0 {
+		sb.WriteByte(' ')
+		sb.WriteString(strings.Join(sections, ";"))
+	}
+	return sb.String()
+}
+
+// countryCodes gives each country the shortest prefix of its name, of the
+// same length for all countries, that tells it apart from the others and
+// from the section keys. If no prefix does, such as when one name is a
+// prefix of another, each country is numbered in board order instead.
+func countryCodes(countries []string) map[string]string {
+	reserved := []string{centersKey, dislodgedKey, contestedKey, rulesKey}
+	longest := 0
+	for _, c := range countries {
+		longest = max(longest, len(c))
+	}
+	for n := 1; n <= longest; n++ {
+		codes := make(map[string]string)
+		seen := make(map[string]bool)
+		unique := true
+		for _, c := range countries {
+			code := c[:min(n, len(c))]
+			key := strings.ToUpper(code)
+			if seen[key] || slices.Contains(reserved, key) {
+				unique = false
+				break
+			}
+			seen[key] = true
+			codes[c] = code
+		}
+		if unique {
+			return codes
+		}
+	}
+	codes := make(map[string]string)
+	for i, c := range countries {
+		codes[c] = strconv.Itoa(i + 1)
+	}
+	return codes
+}
+
+func unitNotation(u *Occupancy) string {
+	s := u.unit.String()[:1] + u.province.abbrs[0]
+	if u.unit == Fleet && u.coast != "" {
+		s += "/" + u.coast
+	}
+	return s
+}
+
+func rulesNotation(r RuleSet) []string {
+	var rules []string
+	if r.Paradox != ParadoxSzykman {
+		rules = append(rules, "Paradox="+r.Paradox.String())
+	}
+	flags := []struct {
+		name string
+		on   bool
+	}{
+		{"BuildAnywhere", r.BuildAnywhere},
+		{"NoCoastNeeded", r.NoCoastNeeded},
+		{"ConvoyViaCoasts", r.ConvoyViaCoasts},
+		{"SelfSupportCut", r.SelfSupportCut},
+		{"DisbandOnNMR", r.DisbandOnNMR},
+	}
+	for _, f := range flags {
+		if f.on {
+			rules = append(rules, f.name)
+		}
+	}
+	if r.VictoryCenters != 0 {
+		rules = append(rules, "VictoryCenters="+strconv.Itoa(r.VictoryCenters))
+	}
+	return rules
+}
+
+func parseRulesNotation(items []string) (RuleSet, error) {
+	var r RuleSet
+	for _, item := range items {
+		name, value, hasValue := strings.Cut(item, "=")
+		var flag *bool
+		switch strings.ToLower(name) {
+		case "paradox":
+			rule, ok := ParseParadoxRule(value)
+			if !ok {
+				return r, fmt.Errorf("unknown paradox rule %s", value)
+			}
+			r.Paradox = rule
+			continue
+		case "victorycenters":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return r, fmt.Errorf("bad victory centers %s", value)
+			}
+			r.VictoryCenters = n
+			continue
+		case "buildanywhere":
+			flag = &r.BuildAnywhere
+		case "nocoastneeded":
+			flag = &r.NoCoastNeeded
+		case "convoyviacoasts":
+			flag = &r.ConvoyViaCoasts
+		case "selfsupportcut":
+			flag = &r.SelfSupportCut
+		case "disbandonnmr":
+			flag = &r.DisbandOnNMR
+		default:
+			return r, fmt.Errorf("unknown rule %s", name)
+		}
+		if hasValue {
+			return r, fmt.Errorf("rule %s takes no value", name)
+		}
+		*flag = true
+	}
+	return r, nil
+}
+
+// ParseNotation reads a game state on the board written by [Game.Notation].
+// Case is ignored, as is space around separators.
+//
+// The state is validated against the board as with [Game.UnmarshalJSON].
+func ParseNotation(board *Board, notation string) (*Game, error) {
+	if board == nil {
+		return nil, errors.New("no board given")
+	}
+	head, body, _ := strings.Cut(strings.TrimSpace(notation), " ")
+	g := NewGame(board)
+	var err error
+	if g.year, g.phase, err = ParseLabel(head); err != nil {
+		return nil, err
+	}
+	for p := range g.centers {
+		g.centers[p] = ""
+	}
+	countries := make(map[string]string)
+	for c, code := range countryCodes(board.countries) {
+		countries[strings.ToUpper(code)] = c
+	}
+	seen := make(map[string]bool)
+	for _, section := range strings.Split(body, ";") {
+		section = strings.TrimSpace(section)
+		if section == "" {
+			continue
+		}
+		key, list, ok := strings.Cut(section, ":")
+		if !ok {
+			return nil, fmt.Errorf("section %s has no ':'", section)
+		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		if seen[key] {
+			return nil, fmt.Errorf("section %s given twice", key)
+		}
+		seen[key] = true
+		var items []string
+		for _, item := range strings.Split(list, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		if (key == dislodgedKey || key == contestedKey) && !g.phase.Retreat() {
+			return nil, errors.New("dislodged units and contested provinces are only allowed in retreat phases")
+		}
+		var err error
+		switch key {
+		case centersKey:
+			err = g.parseCentersNotation(items, countries)
+		case dislodgedKey:
+			err = g.parseDislodgedNotation(items, countries)
+		case contestedKey:
+			for _, item := range items {
+				p := board.abbreviated(item)
+				if p == nil {
+					return nil, fmt.Errorf("no province %s", item)
+				}
+				g.contests[p] = true
+			}
+		case rulesKey:
+			g.rules, err = parseRulesNotation(items)
+		default:
+			country, ok := countries[key]
+			if !ok {
+				return nil, fmt.Errorf("no country %s", key)
+			}
+			for _, item := range items {
+				var u *Occupancy
+				if u, err = g.parseUnitNotation(item, country); err != nil {
+					break
+				}
+				if g.units[u.province] != nil {
+					return nil, fmt.Errorf("two units in %s", u.province.name)
+				}
+				g.units[u.province] = u
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+func (g *Game) parseUnitNotation(item, country string) (*Occupancy, error) {
+	if item == "" {
+		return nil, errors.New("missing unit")
+	}
+	unit, ok := ParseUnit(item[:1])
+	if !ok {
+		return nil, fmt.Errorf("unknown unit type in %s", item)
+	}
+	abbr, coast, _ := strings.Cut(item[1:], "/")
+	p := g.board.abbreviated(abbr)
+	if p == nil {
+		return nil, fmt.Errorf("no province %s", abbr)
+	}
+	if i := slices.IndexFunc(p.coasts, func(c string) bool {
+		return strings.EqualFold(c, coast)
+	}); i >= 0 {
+		coast = p.coasts[i]
+	}
+	u, err := g.validSetUnit(p, coast, unit, country)
+	if err != nil {
+		return nil, fmt.Errorf("unit in %s: %w", p.name, err)
+	}
+	return u, nil
+}
+
+func (g *Game) parseCentersNotation(items []string, countries map[string]string) error {
+	for _, item := range items {
+		abbr, code, _ := strings.Cut(item, "=")
+		p := g.board.abbreviated(abbr)
+		if p == nil {
+			return fmt.Errorf("no province %s", abbr)
+		}
+		if err := g.board.validCenter(p); err != nil {
+			return err
+		}
+		country, ok := countries[strings.ToUpper(code)]
+		if !ok {
+			return fmt.Errorf("no country %s", code)
+		}
+		g.centers[p] = country
+	}
+	return nil
+}
+
+func (g *Game) parseDislodgedNotation(items []string, countries map[string]string) error {
+	for _, item := range items {
+		item, from, hasFrom := strings.Cut(item, "<")
+		item, code, _ := strings.Cut(item, "=")
+		country, ok := countries[strings.ToUpper(code)]
+		if !ok {
+			return fmt.Errorf("no country %s", code)
+		}
+		u, err := g.parseUnitNotation(item, country)
+		if err != nil {
+			return err
+		}
+		if g.dislodged[u.province] != nil {
+			return fmt.Errorf("two dislodged units in %s", u.province.name)
+		}
+		var attacker *Province
+		if hasFrom {
+			if attacker = g.board.abbreviated(from); attacker == nil {
+				return fmt.Errorf("no province %s", from)
+			}
+		}
+		g.dislodged[u.province] = u
+		g.attackers[u] = attacker
+	}
+	return nil
+}
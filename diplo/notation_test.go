@@ -0,0 +1,78 @@
+package diplo
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestCountryCodes(t *testing.T) {
+	tests := []struct {
+		countries []string
+		want      map[string]string
+	}{
+		{
+			[]string{"Austria", "England", "France", "Germany", "Italy", "Russia", "Turkey"},
+			map[string]string{"Austria": "A", "England": "E", "France": "F", "Germany": "G",
+				"Italy": "I", "Russia": "R", "Turkey": "T"},
+		},
+		{
+			[]string{"Rome", "Rhodes", "Carthage"},
+			map[string]string{"Rome": "Ro", "Rhodes": "Rh", "Carthage": "Ca"},
+		},
+		{
+			// No prefix of "D" avoids the dislodged section key.
+			[]string{"D", "Egypt"},
+			map[string]string{"D": "1", "Egypt": "2"},
+		},
+		{
+			// The names are the same but for case.
+			[]string{"Rome", "ROME"},
+			map[string]string{"Rome": "1", "ROME": "2"},
+		},
+	}
+	for _, tt := range tests {
+		if got := countryCodes(tt.countries); !maps.Equal(got, tt.want) {
+			t.Errorf("countryCodes(%q) = %v, want %v", tt.countries, got, tt.want)
+		}
+	}
+}
+
+func TestNotationNumberedCountries(t *testing.T) {
+	// Rename Italy to the dislodged section key.
+	b := standardBuilder()
+	for i, c := range b.Countries {
+		if c == "Italy" {
+			b.Countries[i] = "D"
+		}
+	}
+	b.CountryAliases = map[string][]string{"D": nil}
+	for i := range b.Provinces {
+		if b.Provinces[i].Country == "Italy" {
+			b.Provinces[i].Country = "D"
+		}
+	}
+	for i := range b.Units {
+		if b.Units[i].Country == "Italy" {
+			b.Units[i].Country = "D"
+		}
+	}
+	board, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewStartingGame(board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notation := g.Notation()
+	read, err := ParseNotation(board, notation)
+	if err != nil {
+		t.Fatalf("ParseNotation(%q): %v", notation, err)
+	}
+	if got := read.Notation(); got != notation {
+		t.Errorf("read back as %q, want %q", got, notation)
+	}
+	if u := read.Unit(board.Province("Rome")); u == nil || u.Country() != "D" {
+		t.Errorf("Rome is not held by D in %q", notation)
+	}
+}
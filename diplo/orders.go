@@ -1,6 +1,7 @@
 package diplo
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
@@ -26,6 +27,8 @@ const (
 	Convoy
 	// Build is the construction of a new unit.
 	Build
+	// Waive gives up a country's remaining builds on purpose.
+	Waive
 )
 
 // Order is an instruction for a unit.
@@ -36,6 +39,7 @@ const (
 //     during retreat and build phases.
 //  2. Move order: Unit is the moving unit; Target is the destination
 //     province. Functions as a retreat order during retreat phases.
+//     ViaConvoy may be set to have an Army go by convoy.
 //  3. Support hold order: Unit is the supporting unit. Recipient
 //     is the holding unit being supported.
 //  4. Support move order: Unit is the supporting unit. Recipient
@@ -44,6 +48,7 @@ const (
 //     is the moving Army being convoyed. Target is the destination coastal province.
 //  6. Build order: Unit is nil. Target is the supply center to build on.
 //     Build is the unit type to add.
+//  7. Waive order: Waive is true, and no other field is set.
 type Order struct {
 	// Unit is the unit making the order. Must always be set, except for build orders.
 	Unit *Province
@@ -56,8 +61,14 @@ type Order struct {
 	// Convoy is true if a Fleet is making a convoy order;
 	// if true, Other and Target must be set.
 	Convoy bool
+	// ViaConvoy is true if a moving Army must go by convoy, even to an
+	// adjacent province. An Army is also convoyed to an adjacent province
+	// when a Fleet of its own country is ordered to convoy it there.
+	ViaConvoy bool
 	// The kind of unit to build. Ignored outside of build phases.
 	Build Unit
+	// Waive is true if a country is leaving its remaining builds unused.
+	Waive bool
 }
 
 // OrderHoldDisband creates a hold or disband order.
@@ -76,6 +87,15 @@ func OrderMoveRetreat(unit, destination *Province, coast string) Order {
 	}
 }
 
+// OrderMoveViaConvoy creates a move order for an Army that must go by convoy.
+func OrderMoveViaConvoy(army, destination *Province) Order {
+	return Order{
+		Unit:      army,
+		Target:    destination,
+		ViaConvoy: true,
+	}
+}
+
 // OrderSupportHold creates a support-hold order.
 func OrderSupportHold(supporter, holder *Province) Order {
 	return Order{
@@ -112,9 +132,16 @@ func OrderBuild(province *Province, unit Unit) Order {
 	}
 }
 
+// OrderWaive creates a waive order.
+func OrderWaive() Order {
+	return Order{
+		Waive: true,
+	}
+}
+
 // Kind determines the form of the order (see list in docs for [Order])
 // based on which fields are set. It does not validate the order.
-func (o *Order) Kind() OrderKind {
+func (o Order) Kind() OrderKind {
 	var (
 		u = o.Unit != nil
 		r = o.Recipient != nil
@@ -122,6 +149,11 @@ func (o *Order) Kind() OrderKind {
 		c = o.Convoy
 	)
 	switch {
+	case o.Waive:
+		if !u && !r && !t && !c {
+			return Waive
+		}
+		return InvalidOrder
 	case u && !r && !t && !c:
 		return HoldDisband
 	case u && !r && t && !c:
@@ -139,10 +171,82 @@ func (o *Order) Kind() OrderKind {
 	}
 }
 
+// UnknownProvinceError says a name matches no province on the board.
+type UnknownProvinceError struct {
+	Name string
+	// Suggestions is the provinces the name may be a misspelling of, best
+	// first, if the game suggests them (see [Game.SetSuggestions]).
+	Suggestions []ProvinceMatch
+}
+
+func (e *UnknownProvinceError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("no province %s", e.Name)
+	}
+	names := make([]string, len(e.Suggestions))
+	for i, m := range e.Suggestions {
+		names[i] = m.Province.name
+	}
+	return fmt.Sprintf("no province %s (did you mean %s?)", e.Name, strings.Join(names, " or "))
+}
+
+// AmbiguousProvinceError says a name matches more than one province on the
+// board, and which ones could have been meant.
+type AmbiguousProvinceError struct {
+	Name       string
+	Candidates []*Province
+}
+
+func (e *AmbiguousProvinceError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, p := range e.Candidates {
+		names[i] = p.name
+	}
+	return fmt.Sprintf("ambiguous province %s (could be %s)", e.Name, strings.Join(names, ", "))
+}
+
+// UnknownCoastError says a province is followed by a word that is not a coast.
+type UnknownCoastError struct {
+	Province string
+	Coast    string
+}
+
+func (e *UnknownCoastError) Error() string {
+	return fmt.Sprintf("unknown coast %s of %s", e.Coast, e.Province)
+}
+
+// MissingUnitError says an order names a unit that is not in its province,
+// like "A Mun" when Munich is empty or holds a Fleet.
+type MissingUnitError struct {
+	Province string
+	Unit     Unit
+}
+
+func (e *MissingUnitError) Error() string {
+	return fmt.Sprintf("no %s in %s", strings.ToLower(e.Unit.String()), e.Province)
+}
+
+// UnknownCountryError says a name matches no country on the board.
+type UnknownCountryError struct {
+	Name string
+}
+
+func (e *UnknownCountryError) Error() string {
+	return fmt.Sprintf("no country %s", e.Name)
+}
+
+// maxSuggestions is how many provinces an [UnknownProvinceError] suggests.
+const maxSuggestions = 3
+
 func (g *Game) validParse(name string, valid []*Province) (*Province, error) {
 	ps := g.board.ParseProvince(name)
 	if len(ps) == 0 {
-		return nil, fmt.Errorf("no province %s", name)
+		err := &UnknownProvinceError{Name: name}
+		if g.suggest {
+			err.Suggestions = g.board.MatchProvince(name)
+			err.Suggestions = err.Suggestions[:min(len(err.Suggestions), maxSuggestions)]
+		}
+		return nil, err
 	}
 	if len(ps) > 1 {
 		var matches []*Province
@@ -154,107 +258,216 @@ func (g *Game) validParse(name string, valid []*Province) (*Province, error) {
 		if len(matches) == 1 {
 			return matches[0], nil
 		}
-		return nil, fmt.Errorf("ambiguous province %s", name)
+		if len(matches) == 0 {
+			matches = ps
+		}
+		return nil, &AmbiguousProvinceError{name, matches}
 	}
 	return ps[0], nil
 }
 
+// splitCoast separates a trailing coast name from the words of a province name.
+// The last word is an unknown coast if the words before it name a province,
+// but all of them do not.
+func (g *Game) splitCoast(words []string) (string, string, error) {
+	name := strings.Join(words, " ")
+	if n := len(words); n > 1 {
+		rest := strings.Join(words[:n-1], " ")
+		if len(g.board.ParseProvince(rest)) > 0 {
+			if c, ok := g.board.ParseCoast(words[n-1]); ok {
+				return rest, c, nil
+			}
+			if len(g.board.ParseProvince(name)) == 0 {
+				return "", "", &UnknownCoastError{rest, words[n-1]}
+			}
+		}
+	}
+	return name, "", nil
+}
+
+// parseBuild interprets the unit type and supply center of a build order.
+func (g *Game) parseBuild(build string, coerce string) (*Order, error) {
+	build = strings.NewReplacer("/", " ", "(", " ", ")", " ").Replace(build)
+	words := strings.Fields(build)
+	if len(words) < 2 {
+		return nil, errors.New("build needs a unit type and a province")
+	}
+	unit, ok := ParseUnit(words[0])
+	if !ok {
+		return nil, fmt.Errorf("unknown unit type %s", words[0])
+	}
+	var valid []*Province
+	if coerce != "" {
+		for p := range g.board.Centers() {
+			if g.rules.BuildAnywhere || strings.EqualFold(p.country, coerce) {
+				valid = append(valid, p)
+			}
+		}
+	}
+	name, coast, err := g.splitCoast(words[1:])
+	if err != nil {
+		return nil, err
+	}
+	p, err := g.validParse(name, valid)
+	if err != nil {
+		return nil, err
+	}
+	if coast != "" && len(p.Coasts()) == 0 {
+		return nil, &UnknownCoastError{p.name, coast}
+	}
+	o := OrderBuild(p, unit)
+	o.TargetCoast = coast
+	return &o, nil
+}
+
 // ParseOrder can interpret a simple string representation of a unit order.
 //
 // If coerce is a string, ParseOrder will resolve ambiguous province abbreviations
 // by accepting only the ones which the unit could travel to, using the string value
 // as the nation making the order. Coast names are always coerced (during resolution).
 //
-// ParseOrder cannot parse build or disband orders during a [Winter] phase.
-//
 // Parse order supports the order format described in the rulebook:
-// A/F Unit [S/C Other] - Target
+// A/F Unit [S/C A/F Other] - Target
 //
-// The unit prefix A/F may be omitted, and an arrow (-> or -->) may replace the hyphen.
+// The unit prefixes A/F may be omitted, and an arrow (-> or -->) may replace the hyphen.
+// A prefix given for the ordered unit must match it, or a [*MissingUnitError] is
+// returned. A coast may only follow a province that has named coasts.
+// Hold orders may be written with or without a trailing H or Hold, and disband
+// orders with a trailing D or Disband. Coasts follow
+// a province name, separated by a space, a slash, or in parentheses.
+// A move may end with "via convoy" to have an Army go by convoy.
+//
+// In retreat and build phases, a disband may instead start with Disband or
+// Remove, like "Disband F Nth". In a [Winter] phase, a build is written
+// Build (or B), the unit type, and the supply center, like "Build F StP/NC",
+// and "Waive" leaves the country's remaining builds unused (see [OrderWaive]).
 func (g *Game) ParseOrder(order string, coerce string) (*Order, error) {
 	order = strings.ToLower(order)
+	if !g.phase.Move() {
+		first, rest, _ := strings.Cut(strings.TrimSpace(order), " ")
+		switch {
+		case first == "disband" || first == "remove":
+			order = rest
+		case g.phase == Winter && (first == "build" || first == "b"):
+			return g.parseBuild(rest, coerce)
+		case g.phase == Winter && first == "waive" && strings.TrimSpace(rest) == "":
+			o := OrderWaive()
+			return &o, nil
+		}
+	}
+	order, viaConvoy := strings.CutSuffix(strings.TrimSpace(order), "via convoy")
+	// Hyphens in province names are not separators.
+	for _, p := range g.board.provinces {
+		if name := strings.ToLower(p.name); strings.Contains(name, "-") {
+			order = strings.ReplaceAll(order, name, strings.ReplaceAll(name, "-", " "))
+		}
+	}
 	// Put space around hyphens and arrows for easier processing.
-	// Sometimes coast designations use parentheses.
-	order = strings.ReplaceAll(order, "-", " - ")
-	order = strings.ReplaceAll(order, "--", " - ")
-	order = strings.ReplaceAll(order, "->", " - ")
+	// Sometimes coast designations use parentheses or slashes.
 	order = strings.ReplaceAll(order, "-->", " - ")
+	order = strings.ReplaceAll(order, "->", " - ")
+	order = strings.ReplaceAll(order, "--", " - ")
+	order = strings.ReplaceAll(order, "-", " - ")
 	order = strings.ReplaceAll(order, "(", " ")
 	order = strings.ReplaceAll(order, ")", " ")
+	order = strings.ReplaceAll(order, "/", " ")
 	// Process parts.
 	var (
-		unit, recipient, target, coast = "", "", "", ""
-		convoy                         = false
-		mode                           = 0
+		unitW, recipientW, targetW []string
+		unitType                   Unit
+		typed                      = false
+		convoy                     = false
+		mode                       = 0
 	)
-	for i, p := range strings.Fields(order) {
-		// Ignore unit prefix.
-		if i == 0 && (p == "a" || p == "f") {
-			continue
-		}
-		if c, ok := g.board.ParseCoast(p); ok {
-			// Coast only needed for target.
-			if mode == 2 {
-				coast = c
+	for _, p := range strings.Fields(order) {
+		switch {
+		case p == "a" || p == "f":
+			// Unit prefixes are checked against the ordered unit only.
+			if mode == 0 && len(unitW) == 0 {
+				unitType, typed = Army, true
+				if p == "f" {
+					unitType = Fleet
+				}
+				continue
 			}
+			if mode == 1 && len(recipientW) == 0 {
+				continue
+			}
+		case p == "-":
+			mode = 2
+			continue
+		case mode == 0 && p == "s":
+			mode = 1
+			continue
+		case mode == 0 && p == "c":
+			mode = 1
+			convoy = true
+			continue
+		case mode == 0 && (p == "h" || p == "hold" || p == "holds" || p == "d" || p == "disband"):
 			continue
 		}
 		switch mode {
 		case 0:
-			if unit == "" {
-				unit = p
-			} else if p == "s" {
-				mode = 1
-			} else if p == "c" {
-				mode = 1
-				convoy = true
-			} else {
-				unit += " " + p
-			}
+			unitW = append(unitW, p)
 		case 1:
-			if recipient == "" {
-				recipient = p
-			} else if p == "-" {
-				mode = 2
-			} else {
-				recipient += " " + p
-			}
+			recipientW = append(recipientW, p)
 		case 2:
-			if target == "" {
-				target = p
-			} else {
-				target += " " + p
-			}
+			targetW = append(targetW, p)
 		}
 	}
+	// Coasts are only needed for the target.
+	unit, _, err := g.splitCoast(unitW)
+	if err != nil {
+		return nil, err
+	}
+	recipient, _, err := g.splitCoast(recipientW)
+	if err != nil {
+		return nil, err
+	}
+	target, coast, err := g.splitCoast(targetW)
+	if err != nil {
+		return nil, err
+	}
 	o := &Order{
 		TargetCoast: coast,
 		Convoy:      convoy,
+		ViaConvoy:   viaConvoy,
 	}
-	var err error
 	// Unit is required since the only order which does not require a unit, a build order,
 	// cannot be parsed.
 	var unitV []*Province
 	for u := range g.Units(coerce) {
 		unitV = append(unitV, u.province)
 	}
+	for u := range g.AllDislodged() {
+		if strings.EqualFold(u.country, coerce) {
+			unitV = append(unitV, u.province)
+		}
+	}
 	if o.Unit, err = g.validParse(unit, unitV); err != nil {
 		return nil, err
 	}
+	u := g.orderedUnit(o.Unit)
+	if typed && (u == nil || u.unit != unitType) {
+		return nil, &MissingUnitError{o.Unit.name, unitType}
+	}
 	if target != "" {
 		var targetV []*Province
-		if coerce == "" {
+		if coerce == "" || u == nil {
 			targetV = nil
-		} else if recipient == "" {
+		} else if recipient == "" && !g.phase.Retreat() {
 			// Move order; target can be across water.
-			targetV = slices.Collect(g.Destinations(g.Unit(o.Unit)))
+			targetV = slices.Collect(g.Destinations(u))
 		} else {
-			// Support-move order; target must be neighbor.
-			targetV = slices.Collect(g.Neighbors(g.Unit(o.Unit)))
+			// Support-move or retreat order; target must be neighbor.
+			targetV = slices.Collect(g.Neighbors(u))
 		}
 		if o.Target, err = g.validParse(target, targetV); err != nil {
 			return nil, err
 		}
+		if coast != "" && len(o.Target.Coasts()) == 0 {
+			return nil, &UnknownCoastError{o.Target.name, coast}
+		}
 	}
 	if recipient != "" {
 		var recipientV []*Province
@@ -263,7 +476,6 @@ func (g *Game) ParseOrder(order string, coerce string) (*Order, error) {
 		} else if target == "" {
 			// Support-hold order; recipient must be a neighbor
 			// of the supporting unit.
-			u := g.Unit(o.Unit)
 			if u != nil {
 				for n := range g.Neighbors(u) {
 					if g.Unit(n) != nil {
@@ -274,7 +486,6 @@ func (g *Game) ParseOrder(order string, coerce string) (*Order, error) {
 		} else {
 			// Support-move order; recipient must have target
 			// as a destination.
-			u := g.Unit(o.Unit)
 			for ru := range g.AllUnits() {
 				if ru == u {
 					continue
@@ -290,3 +501,77 @@ func (g *Game) ParseOrder(order string, coerce string) (*Order, error) {
 	}
 	return o, nil
 }
+
+// ParsedOrder is a line of orders read by [Game.ParseOrders].
+type ParsedOrder struct {
+	// Line is the line of the text the order is on, counting from 1.
+	Line int
+	// Text is the order as written, without comments or surrounding space.
+	Text string
+	// Country is the country giving the order, or empty under the header
+	// of an unknown country.
+	Country string
+	// Order is the order, or nil if it could not be parsed.
+	Order *Order
+	// Err is why the order could not be parsed, such as an
+	// [*UnknownProvinceError], [*AmbiguousProvinceError], [*UnknownCoastError],
+	// [*MissingUnitError], or [*UnknownCountryError] for a country header.
+	Err error
+}
+
+// ParseOrders interprets a block of orders, one per line, as with
+// [Game.ParseOrder]. Each order is coerced for the country giving it.
+//
+// Orders are given by country until a line naming another country followed
+// by a colon, like "England:". Blank lines, phase headers like
+// "Spring 1901 Movement", and text following "#" or "//" are skipped, as is
+// an outcome following "=>" (see [WriteRecord]).
+//
+// There is a result for every order line and every header naming an unknown
+// country, in the order they are written. Order lines under a header naming an
+// unknown country are not parsed; their results have no country and the same
+// [*UnknownCountryError] as the header.
+func (g *Game) ParseOrders(text string, country string) []ParsedOrder {
+	var (
+		results []ParsedOrder
+		unknown error // for the header of the lines being read
+	)
+	for i, line := range strings.Split(text, "\n") {
+		for _, comment := range []string{"#", "//"} {
+			if j := strings.Index(line, comment); j >= 0 {
+				line = line[:j]
+			}
+		}
+		line, _, _ = strings.Cut(line, "=>")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if _, _, ok := parsePhaseHeader(line); ok {
+			continue
+		}
+		if name, ok := strings.CutSuffix(line, ":"); ok {
+			c, ok := g.board.ParseCountry(name)
+			if !ok || !hasStringFold(g.board.countries, c) {
+				country, unknown = "", &UnknownCountryError{strings.TrimSpace(name)}
+				results = append(results, ParsedOrder{Line: i + 1, Text: line, Err: unknown})
+				continue
+			}
+			country, unknown = c, nil
+			continue
+		}
+		if unknown != nil {
+			results = append(results, ParsedOrder{Line: i + 1, Text: line, Err: unknown})
+			continue
+		}
+		order, err := g.ParseOrder(line, country)
+		results = append(results, ParsedOrder{
+			Line:    i + 1,
+			Text:    line,
+			Country: country,
+			Order:   order,
+			Err:     err,
+		})
+	}
+	return results
+}
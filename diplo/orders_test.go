@@ -0,0 +1,82 @@
+package diplo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseOrderErrors(t *testing.T) {
+	tests := []struct {
+		notation string
+		order    string
+		err      any
+	}{
+		{"S1901M G:FKie;SC:", "F Kie - Den/sc", new(*UnknownCoastError)},
+		{"S1901M G:FKie;SC:", "F Kie - Den(NC)", new(*UnknownCoastError)},
+		{"S1901M G:FKie;SC:", "A Kie - Den", new(*MissingUnitError)},
+		{"S1901M G:FKie;SC:", "A Mun H", new(*MissingUnitError)},
+		{"W1901A G:AKie;SC:Ber=G,Mun=G", "Remove A Mun", new(*MissingUnitError)},
+		{"W1901A G:AKie;SC:Ber=G,Mun=G,Kie=G", "Build A Mun/sc", new(*UnknownCoastError)},
+	}
+	for _, tt := range tests {
+		g, err := ParseNotation(StandardBoard, tt.notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, country := range []string{"", "Germany"} {
+			o, err := g.ParseOrder(tt.order, country)
+			if !errors.As(err, tt.err) {
+				t.Errorf("ParseOrder(%q, %q) = %v, %v; want %T", tt.order, country, o, err, tt.err)
+			}
+		}
+	}
+}
+
+func TestParseOrderCoasts(t *testing.T) {
+	g, err := ParseNotation(StandardBoard, "S1901M F:FMAO;SC:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for order, coast := range map[string]string{
+		"F MAO - Spa/sc":  "SC",
+		"F MAO - Spa(NC)": "NC",
+		"F MAO - Bre":     "",
+	} {
+		o, err := g.ParseOrder(order, "France")
+		if err != nil {
+			t.Errorf("ParseOrder(%q): %v", order, err)
+			continue
+		}
+		if o.TargetCoast != coast {
+			t.Errorf("ParseOrder(%q).TargetCoast = %q, want %q", order, o.TargetCoast, coast)
+		}
+	}
+}
+
+func TestParseOrdersUnknownCountry(t *testing.T) {
+	g := StandardGame()
+	results := g.ParseOrders("France:\nA Par - Bur\nFoo:\nA Mar - Spa\nGermany:\nA Mun - Ruh\n", "")
+	want := []struct {
+		country string
+		ok      bool
+	}{
+		{"France", true},
+		{"", false}, // the header
+		{"", false},
+		{"Germany", true},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, r := range results {
+		var unknown *UnknownCountryError
+		switch {
+		case r.Country != want[i].country:
+			t.Errorf("line %d: country %q, want %q", r.Line, r.Country, want[i].country)
+		case want[i].ok && (r.Err != nil || r.Order == nil):
+			t.Errorf("line %d: %v", r.Line, r.Err)
+		case !want[i].ok && (r.Order != nil || !errors.As(r.Err, &unknown) || unknown.Name != "Foo"):
+			t.Errorf("line %d: got %v, %v; want unknown country Foo", r.Line, r.Order, r.Err)
+		}
+	}
+}
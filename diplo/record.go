@@ -0,0 +1,288 @@
+package diplo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var phaseHeaders = [...]string{
+	Spring:         "Spring %d Movement",
+	SpringRetreats: "Spring %d Retreats",
+	Fall:           "Fall %d Movement",
+	FallRetreats:   "Fall %d Retreats",
+	Winter:         "Winter %d Adjustments",
+}
+
+// RecordError is an error in a game record read by [ReadRecord].
+type RecordError struct {
+	// Line is the line of the record the error is on, counting from 1.
+	Line int
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// WriteRecord writes the main line of a history (see [History.MainLine]) as
+// a plain-text game record, for example:
+//
+//	[Board "Standard"]
+//	[Start "S1901M A:ABud,FTri,AVie;...;SC:Ank=T,Ber=G,..."]
+//
+//	Spring 1901 Movement
+//	Austria:
+//	  A Bud - Ser => Success
+//	  F Tri H => Success
+//	  A Vie - Gal => Standoff
+//	...
+//
+// The record begins with tags: the name the board was registered with, if
+// any (see [RegisterBoard]), and the starting position (see [Game.Notation]).
+// Then comes each phase in which orders were given, headed by its season,
+// year, and kind, with each country's orders in the format accepted by
+// [Game.ParseOrder], followed by "=>" and the outcome.
+//
+// Builds are written as "Build", the unit type, and the province, like
+// "Build F StP(NC)", and disbands as the unit followed by "D".
+func WriteRecord(w io.Writer, h *History) error {
+	var sb strings.Builder
+	root := h.Root().Game()
+	if name, ok := boardName(root.board); ok {
+		fmt.Fprintf(&sb, "[Board %q]\n", name)
+	}
+	fmt.Fprintf(&sb, "[Start %q]\n", root.Notation())
+	line := h.MainLine()
+	for i, p := range line[1:] {
+		game := line[i].Game()
+		if len(p.orders) == 0 {
+			continue
+		}
+		sb.WriteByte('\n')
+		fmt.Fprintf(&sb, phaseHeaders[game.phase]+"\n", game.year)
+		country := ""
+		for _, c := range game.board.countries {
+			for _, r := range p.orders {
+				if r.Country != c {
+					continue
+				}
+				if country != c {
+					country = c
+					fmt.Fprintf(&sb, "%s:\n", c)
+				}
+				fmt.Fprintf(&sb, "  %s => %v\n", r.Order.Format(game, StyleWebDip), r.Outcome)
+			}
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ReadRecord reads a game record written by [WriteRecord], replaying each
+// phase's orders with [Arena.Add] and [Arena.Go] to rebuild the history.
+//
+// If board is nil, the board is found by the name in the record's Board tag.
+// Other tags are ignored, and so is text following "#". Phases with no orders
+// may be left out; units without orders are given default orders.
+//
+// Errors are given as a [*RecordError] with the line they are on, including
+// orders that do not parse and outcomes that differ from adjudication.
+func ReadRecord(r io.Reader, board *Board) (*History, error) {
+	rr := &recordReader{board: board}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		rr.n += 1
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if err := rr.line(text); err != nil {
+			if _, ok := err.(*RecordError); ok {
+				return nil, err
+			}
+			return nil, &RecordError{rr.n, err}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if rr.history == nil {
+		return nil, &RecordError{rr.n, errors.New("no phases")}
+	}
+	if err := rr.play(); err != nil {
+		return nil, err
+	}
+	return rr.history, nil
+}
+
+type recordExpectation struct {
+	n       int
+	country string
+	order   Order
+	outcome Outcome
+}
+
+type recordReader struct {
+	board    *Board
+	start    string
+	n        int
+	history  *History
+	position *Position
+	arena    *Arena
+	country  string
+	expected []recordExpectation
+}
+
+func (rr *recordReader) line(text string) error {
+	if strings.HasPrefix(text, "[") {
+		return rr.tag(text)
+	}
+	if year, phase, ok := parsePhaseHeader(text); ok {
+		return rr.header(year, phase)
+	}
+	if name, ok := strings.CutSuffix(text, ":"); ok {
+		if rr.history == nil {
+			return errors.New("country before phase")
+		}
+		country, ok := rr.board.ParseCountry(name)
+		if !ok || !hasStringFold(rr.board.countries, country) {
+			return &UnknownCountryError{name}
+		}
+		rr.country = country
+		return nil
+	}
+	if rr.arena == nil {
+		return errors.New("order before phase")
+	}
+	if rr.country == "" {
+		return errors.New("order before country")
+	}
+	text, result, hasResult := strings.Cut(text, "=>")
+	order, err := rr.position.game.ParseOrder(strings.TrimSpace(text), rr.country)
+	if err != nil {
+		return err
+	}
+	if _, err := rr.arena.Add(rr.country, *order); err != nil {
+		return err
+	}
+	if hasResult {
+		outcome, ok := ParseOutcome(strings.TrimSpace(result))
+		if !ok {
+			return fmt.Errorf("unknown outcome %s", strings.TrimSpace(result))
+		}
+		rr.expected = append(rr.expected, recordExpectation{rr.n, rr.country, *order, outcome})
+	}
+	return nil
+}
+
+func (rr *recordReader) tag(text string) error {
+	name, value, ok := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(text, "["), "]"), " ")
+	if !ok || !strings.HasSuffix(text, "]") {
+		return fmt.Errorf("bad tag %s", text)
+	}
+	value, err := strconv.Unquote(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("bad tag value in %s", text)
+	}
+	if rr.history != nil {
+		return errors.New("tag after phase")
+	}
+	switch strings.ToLower(name) {
+	case "board":
+		if rr.board == nil {
+			board, ok := RegisteredBoard(value)
+			if !ok {
+				return fmt.Errorf("no board registered as %s", value)
+			}
+			rr.board = board
+		}
+	case "start":
+		rr.start = value
+	}
+	return nil
+}
+
+func parsePhaseHeader(text string) (int, Phase, bool) {
+	fields := strings.Fields(text)
+	if len(fields) != 3 {
+		return 0, 0, false
+	}
+	year, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	text = strings.Join(fields, " ")
+	for p, h := range phaseHeaders {
+		if strings.EqualFold(fmt.Sprintf(h, year), text) {
+			return year, Phase(p), true
+		}
+	}
+	return 0, 0, false
+}
+
+// header starts the next phase, playing out those before it.
+func (rr *recordReader) header(year int, phase Phase) error {
+	if rr.history == nil {
+		if rr.board == nil {
+			return errors.New("no board")
+		}
+		if rr.start == "" {
+			return errors.New("no starting position")
+		}
+		start, err := ParseNotation(rr.board, rr.start)
+		if err != nil {
+			return fmt.Errorf("starting position: %w", err)
+		}
+		rr.history = NewHistory(start)
+		rr.position = rr.history.Root()
+		rr.arena = start.Arena()
+	} else if err := rr.play(); err != nil {
+		return err
+	}
+	for {
+		g := rr.position.game
+		switch {
+		case g.year == year && g.phase == phase:
+			rr.country = ""
+			return nil
+		case g.year > year || g.year == year && g.phase > phase:
+			return fmt.Errorf("phase %s is out of order", fmt.Sprintf(phaseHeaders[phase], year))
+		}
+		if err := rr.play(); err != nil {
+			return err
+		}
+	}
+}
+
+// play adjudicates the current phase and checks the recorded outcomes.
+func (rr *recordReader) play() error {
+	next, err := rr.position.Play(rr.arena)
+	if err != nil {
+		return err
+	}
+	for _, e := range rr.expected {
+		outcome := rr.arena.Outcomes(e.country)[e.order]
+		if outcome != e.outcome {
+			return &RecordError{e.n, fmt.Errorf(
+				"%s: recorded %v, adjudicated %v",
+				e.order.Format(rr.position.game, StyleWebDip), e.outcome, outcome,
+			)}
+		}
+	}
+	rr.expected = nil
+	rr.position = next
+	rr.arena = next.game.Arena()
+	return nil
+}
@@ -0,0 +1,49 @@
+package diplo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	g, err := ParseNotation(StandardBoard, "W1901A E:FNth,AYor,FNwg;SC:Lon=E,Edi=E,Lvp=E,Nwy=E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHistory(g)
+	a := g.Arena()
+	// England has one build, so the second is refused; the record must
+	// replay them in the order they were given.
+	for _, text := range []string{"Build F Lon", "Build A Edi"} {
+		if _, err := a.Add("England", mustParseOrder(t, g, "England", text)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	spring, err := h.Root().Play(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := spring.Game().Arena()
+	b.Add("England", mustParseOrder(t, spring.Game(), "England", "F Lon - ENG"))
+	if _, err := spring.Play(b); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteRecord(&buf, h); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadRecord(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("ReadRecord: %v\n%s", err, buf.String())
+	}
+	want, got := h.MainLine(), read.MainLine()
+	if len(got) != len(want) {
+		t.Fatalf("read %d states, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if w, g := want[i].Game().Notation(), got[i].Game().Notation(); w != g {
+			t.Errorf("state %d: read %s, want %s", i, g, w)
+		}
+	}
+}
@@ -0,0 +1,551 @@
+package diplo
+
+import "slices"
+
+// resolveState is how far the resolver has gotten with a unit's order.
+type resolveState int
+
+const (
+	unresolved resolveState = iota
+	guessing
+	resolved
+)
+
+// resolver adjudicates the orders of a move phase.
+//
+// It uses the guess-and-check algorithm described by Lucas Kruijswijk in
+// "The Math of Adjudication". Every unit has exactly one order (units without
+// a valid order hold), and the resolver decides whether each order succeeds:
+// a move succeeds if the unit enters its target, a support or convoy succeeds
+// if it is not cut or disrupted, and a hold succeeds if the unit is not dislodged.
+//
+// Orders that depend on each other in a cycle are resolved by guessing. When
+// both guesses are consistent (or neither is), a backup rule decides the cycle.
+type resolver struct {
+	game     *Game
+	rules    RuleSet
+	orders   map[*Occupancy]Order
+	into     map[*Province][]*Occupancy  // units moving to each province
+	support  map[*Occupancy][]*Occupancy // matching supports for each unit's order
+	void     map[*Occupancy]bool         // supports and convoys that match no order
+	convoyed map[*Occupancy]bool         // armies that need a convoy to move
+	paradox  map[*Occupancy]bool         // convoyed armies caught in a paradox
+	noCut    map[*Occupancy]bool         // convoyed armies that cannot cut support against their convoy
+	failed   map[*Occupancy]bool         // convoys that fail because of a paradox
+	units    []*Occupancy                // in a fixed order, so results do not depend on map order
+	state    map[*Occupancy]resolveState
+	result   map[*Occupancy]bool
+	started  map[*Occupancy]int // when guessing began for each unit
+	clock    int
+	deps     []*Occupancy // guessing units that results have depended on
+}
+
+func newResolver(game *Game, orders map[*Occupancy]Order, rules RuleSet) *resolver {
+	r := &resolver{
+		game:     game,
+		rules:    rules,
+		orders:   make(map[*Occupancy]Order),
+		into:     make(map[*Province][]*Occupancy),
+		support:  make(map[*Occupancy][]*Occupancy),
+		void:     make(map[*Occupancy]bool),
+		convoyed: make(map[*Occupancy]bool),
+		paradox:  make(map[*Occupancy]bool),
+		noCut:    make(map[*Occupancy]bool),
+		failed:   make(map[*Occupancy]bool),
+		state:    make(map[*Occupancy]resolveState),
+		result:   make(map[*Occupancy]bool),
+		started:  make(map[*Occupancy]int),
+	}
+	// Unordered units hold.
+	for _, p := range game.board.provinces {
+		u := game.units[p]
+		if u == nil {
+			continue
+		}
+		r.units = append(r.units, u)
+		if o, ok := orders[u]; ok {
+			r.orders[u] = o
+		} else {
+			r.orders[u] = OrderHoldDisband(u.province)
+		}
+	}
+	for _, u := range r.units {
+		o := r.orders[u]
+		if o.Kind() != MoveRetreat {
+			continue
+		}
+		r.into[o.Target] = append(r.into[o.Target], u)
+		if u.unit == Army && (!game.HasNeighbor(u, o.Target) || o.ViaConvoy || r.intent(u)) {
+			r.convoyed[u] = true
+		}
+	}
+	// Match supports and convoys to the orders they aid.
+	for _, u := range r.units {
+		o := r.orders[u]
+		switch o.Kind() {
+		case SupportHold, SupportMove, Convoy:
+			recipient := game.units[o.Recipient]
+			if recipient == nil || !r.matches(o, r.orders[recipient]) {
+				r.void[u] = true
+				continue
+			}
+			if o.Kind() != Convoy {
+				r.support[recipient] = append(r.support[recipient], u)
+			}
+		}
+	}
+	return r
+}
+
+// matches tells whether a support or convoy order aids the recipient's order.
+func (r *resolver) matches(o, recipient Order) bool {
+	switch o.Kind() {
+	case SupportHold:
+		return recipient.Kind() != MoveRetreat
+	case SupportMove:
+		// Supports need not name a coast, but may not name the wrong one.
+		coast := o.TargetCoast == "" || recipient.TargetCoast == "" ||
+			o.TargetCoast == recipient.TargetCoast
+		return recipient.Kind() == MoveRetreat && recipient.Target == o.Target && coast
+	case Convoy:
+		return recipient.Kind() == MoveRetreat && recipient.Target == o.Target &&
+			r.game.units[o.Recipient].unit == Army
+	default:
+		return false
+	}
+}
+
+// intent tells whether an army moving to an adjacent province means to go
+// by convoy: a fleet of its own country is ordered to convoy it, and there
+// is a route through fleets ordered to convoy it.
+func (r *resolver) intent(u *Occupancy) bool {
+	o := r.orders[u]
+	own := false
+	for _, f := range r.units {
+		fo := r.orders[f]
+		if f.country == u.country && fo.Kind() == Convoy &&
+			fo.Recipient == u.province && fo.Target == o.Target {
+			own = true
+			break
+		}
+	}
+	return own && r.route(u, func(*Occupancy) bool { return true }) != nil
+}
+
+// resolve decides whether a unit's order succeeds.
+func (r *resolver) resolve(u *Occupancy) bool {
+	switch r.state[u] {
+	case resolved:
+		return r.result[u]
+	case guessing:
+		r.deps = append(r.deps, u)
+		return r.result[u]
+	}
+	old := len(r.deps)
+	// Guess that the order fails.
+	r.result[u] = false
+	r.state[u] = guessing
+	r.started[u] = r.clock
+	r.clock++
+	first := r.adjudicate(u)
+	if len(r.deps) == old {
+		// Result does not depend on any guess.
+		if r.state[u] != resolved {
+			r.result[u] = first
+			r.state[u] = resolved
+		}
+		return first
+	}
+	if r.outer(u, old) {
+		// Part of a cycle, but another order started it.
+		r.deps = append(r.deps, u)
+		r.result[u] = first
+		return first
+	}
+	// Start of a cycle; try guessing that the order succeeds.
+	r.unwind(old)
+	r.result[u] = true
+	r.state[u] = guessing
+	second := r.adjudicate(u)
+	if r.outer(u, old) {
+		r.deps = append(r.deps, u)
+		r.result[u] = second
+		return second
+	}
+	if first == second {
+		// Only one consistent result.
+		r.unwind(old)
+		r.result[u] = first
+		r.state[u] = resolved
+		return first
+	}
+	r.backup(old)
+	return r.resolve(u)
+}
+
+// outer tells whether a unit's result depended on a guess made
+// before its own, since the dependency list had the given length.
+func (r *resolver) outer(u *Occupancy, old int) bool {
+	for _, d := range r.deps[old:] {
+		if r.started[d] < r.started[u] {
+			return true
+		}
+	}
+	return false
+}
+
+// unwind forgets the guesses made since the dependency list had the given length.
+func (r *resolver) unwind(old int) {
+	for _, d := range r.deps[old:] {
+		r.state[d] = unresolved
+	}
+	r.deps = r.deps[:old]
+}
+
+// backup decides a cycle of orders that has either no consistent result or two.
+//
+// A cycle of moves is circular movement, and every move in it succeeds.
+// A cycle involving a convoy is a paradox, decided by the [ParadoxRule].
+func (r *resolver) backup(old int) {
+	cycle := r.deps[old:]
+	var convoys []*Occupancy
+	for _, d := range cycle {
+		if r.orders[d].Kind() == Convoy {
+			convoys = append(convoys, d)
+		}
+	}
+	if len(convoys) == 0 {
+		for _, d := range cycle {
+			if r.orders[d].Kind() == MoveRetreat {
+				r.result[d] = true
+				r.state[d] = resolved
+			} else {
+				r.state[d] = unresolved
+			}
+		}
+		r.deps = r.deps[:old]
+		return
+	}
+	switch r.rules.Paradox {
+	case Paradox2000:
+		for _, c := range convoys {
+			r.failed[c] = true
+		}
+		r.unwind(old)
+		return
+	case ParadoxDPTG:
+		// Try again with the armies unable to cut support against their convoys.
+		again := false
+		for _, c := range convoys {
+			army := r.game.units[r.orders[c].Recipient]
+			if !r.noCut[army] {
+				r.noCut[army] = true
+				again = true
+			}
+		}
+		if again {
+			r.unwind(old)
+			return
+		}
+	}
+	for _, c := range convoys {
+		r.paradox[r.game.units[r.orders[c].Recipient]] = true
+	}
+	r.unwind(old)
+}
+
+func (r *resolver) adjudicate(u *Occupancy) bool {
+	o := r.orders[u]
+	switch o.Kind() {
+	case MoveRetreat:
+		return r.adjudicateMove(u, o)
+	case SupportHold, SupportMove:
+		return r.adjudicateSupport(u, o)
+	case Convoy:
+		return !r.void[u] && !r.failed[u] && r.dislodger(u) == nil
+	default:
+		return r.dislodger(u) == nil
+	}
+}
+
+func (r *resolver) adjudicateMove(u *Occupancy, o Order) bool {
+	if !r.path(u) {
+		return false
+	}
+	attack := r.attackStrength(u)
+	if opp := r.headToHead(u); opp != nil {
+		if attack <= r.defendStrength(opp) {
+			return false
+		}
+	} else if attack <= r.holdStrength(o.Target) {
+		return false
+	}
+	for _, m := range r.into[o.Target] {
+		if m != u && attack <= r.preventStrength(m) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *resolver) adjudicateSupport(u *Occupancy, o Order) bool {
+	if r.void[u] {
+		return false
+	}
+	for _, m := range r.into[u.province] {
+		if r.cuts(m, u, o) {
+			return false
+		}
+	}
+	return r.dislodger(u) == nil
+}
+
+// cuts tells whether a moving unit's attack cuts a unit's support.
+func (r *resolver) cuts(m, u *Occupancy, o Order) bool {
+	if m.country == u.country && !r.rules.SelfSupportCut {
+		return false
+	}
+	// An attack from where the support is directed only cuts it by dislodging,
+	// unless it comes by convoy.
+	if o.Kind() == SupportMove && m.province == o.Target && !r.convoyed[m] {
+		return false
+	}
+	if (r.rules.Paradox == Paradox1982 || r.noCut[m]) && r.againstConvoy(m, o) {
+		return false
+	}
+	return r.path(m)
+}
+
+// dislodger gets the unit that successfully moves into a unit's province, if any.
+func (r *resolver) dislodger(u *Occupancy) *Occupancy {
+	if r.orders[u].Kind() == MoveRetreat && r.resolve(u) {
+		return nil
+	}
+	for _, m := range r.into[u.province] {
+		if r.resolve(m) {
+			return m
+		}
+	}
+	return nil
+}
+
+// againstConvoy tells whether a support is for an attack on a fleet
+// convoying the army.
+func (r *resolver) againstConvoy(army *Occupancy, support Order) bool {
+	if !r.convoyed[army] || support.Kind() != SupportMove {
+		return false
+	}
+	f := r.game.units[support.Target]
+	if f == nil {
+		return false
+	}
+	fo := r.orders[f]
+	return fo.Kind() == Convoy && fo.Recipient == army.province
+}
+
+// path tells whether a moving unit can reach its target, either directly
+// or through a chain of successful convoys.
+func (r *resolver) path(u *Occupancy) bool {
+	if !r.convoyed[u] {
+		return r.game.HasNeighbor(u, r.orders[u].Target)
+	}
+	if r.paradox[u] {
+		return false
+	}
+	return r.route(u, r.resolve) != nil
+}
+
+// route finds a chain of fleets ordered to convoy an army to its target,
+// using only the fleets for which ok is true. It gets nil if there is none.
+func (r *resolver) route(u *Occupancy, ok func(*Occupancy) bool) []*Province {
+	o := r.orders[u]
+	if u.province.terrain != Coastal || o.Target.terrain != Coastal {
+		return nil
+	}
+	var (
+		nodes   []*Province
+		next    = []*Province{u.province}
+		visited = map[*Province]*Province{u.province: nil} // previous province in the chain
+	)
+	for len(next) > 0 {
+		nodes, next = next, nil
+		for _, n := range nodes {
+			for c := range r.game.board.ConnectionsFrom(n) {
+				to := c.to
+				if to == o.Target && n != u.province {
+					var chain []*Province
+					for p := n; p != u.province; p = visited[p] {
+						chain = append(chain, p)
+					}
+					slices.Reverse(chain)
+					return chain
+				}
+				if _, ok := visited[to]; ok || !r.game.convoyable(to) {
+					continue
+				}
+				visited[to] = n
+				f := r.game.units[to]
+				if f == nil || f.unit != Fleet {
+					continue
+				}
+				fo := r.orders[f]
+				if fo.Kind() != Convoy || fo.Recipient != u.province || fo.Target != o.Target {
+					continue
+				}
+				if ok(f) {
+					next = append(next, to)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// headToHead gets the unit moving directly into a moving unit's province
+// from its target, without either being convoyed.
+func (r *resolver) headToHead(u *Occupancy) *Occupancy {
+	if r.convoyed[u] {
+		return nil
+	}
+	opp := r.game.units[r.orders[u].Target]
+	if opp == nil || r.convoyed[opp] {
+		return nil
+	}
+	if o := r.orders[opp]; o.Kind() != MoveRetreat || o.Target != u.province {
+		return nil
+	}
+	return opp
+}
+
+// supporters gets the units successfully supporting a unit's order,
+// ignoring those of the excluded country.
+func (r *resolver) supporters(u *Occupancy, exclude string) []*Occupancy {
+	var ss []*Occupancy
+	for _, s := range r.support[u] {
+		if s.country == exclude {
+			continue
+		}
+		if r.resolve(s) {
+			ss = append(ss, s)
+		}
+	}
+	return ss
+}
+
+// supports counts the successful supports of a unit's order,
+// ignoring those given by the excluded country.
+func (r *resolver) supports(u *Occupancy, exclude string) int {
+	return len(r.supporters(u, exclude))
+}
+
+func (r *resolver) holdStrength(p *Province) int {
+	d := r.game.units[p]
+	if d == nil {
+		return 0
+	}
+	if r.orders[d].Kind() == MoveRetreat {
+		if r.resolve(d) {
+			return 0
+		}
+		return 1
+	}
+	return 1 + r.supports(d, "")
+}
+
+func (r *resolver) attackStrength(u *Occupancy) int {
+	ss, ok := r.attackSupporters(u)
+	if !ok {
+		return 0
+	}
+	return 1 + len(ss)
+}
+
+// attackSupporters gets the supports that count towards a move's attack,
+// or false if the attack has no strength at all.
+func (r *resolver) attackSupporters(u *Occupancy) ([]*Occupancy, bool) {
+	if !r.path(u) {
+		return nil, false
+	}
+	if d := r.defender(u); d != nil {
+		// Countries cannot dislodge their own units or help dislodge their units.
+		if d.country == u.country {
+			return nil, false
+		}
+		return r.supporters(u, d.country), true
+	}
+	return r.supporters(u, ""), true
+}
+
+// defender gets the unit that stays in a move's target, if any.
+func (r *resolver) defender(u *Occupancy) *Occupancy {
+	d := r.game.units[r.orders[u].Target]
+	if d == nil {
+		return nil
+	}
+	// The defender stays unless it successfully moves elsewhere.
+	if r.orders[d].Kind() != MoveRetreat || r.headToHead(u) != nil || !r.resolve(d) {
+		return d
+	}
+	return nil
+}
+
+func (r *resolver) defendStrength(u *Occupancy) int {
+	return 1 + r.supports(u, "")
+}
+
+func (r *resolver) preventStrength(u *Occupancy) int {
+	if !r.path(u) {
+		return 0
+	}
+	// A unit that loses a head-to-head battle cannot prevent other moves.
+	if opp := r.headToHead(u); opp != nil && r.resolve(opp) {
+		return 0
+	}
+	return 1 + r.supports(u, "")
+}
+
+// outcome explains the result of a unit's resolved order.
+func (r *resolver) outcome(u *Occupancy) Outcome {
+	o := r.orders[u]
+	ok := r.resolve(u)
+	switch o.Kind() {
+	case MoveRetreat:
+		if ok {
+			return OutcomeSuccess
+		}
+		if !r.path(u) {
+			if u.unit == Army {
+				return OutcomeNoConvoy
+			}
+			return OutcomeBadTarget
+		}
+		for _, m := range r.into[o.Target] {
+			if m != u && r.resolve(m) {
+				return OutcomeOverpowered
+			}
+		}
+		attack := r.attackStrength(u)
+		for _, m := range r.into[o.Target] {
+			if m != u && attack <= r.preventStrength(m) {
+				return OutcomeStandoff
+			}
+		}
+		return OutcomeWeak
+	case SupportHold, SupportMove, Convoy:
+		switch {
+		case ok:
+			return OutcomeSuccess
+		case r.void[u]:
+			return OutcomeBadRecipient
+		case r.dislodger(u) != nil:
+			return OutcomeDislodged
+		default:
+			return OutcomeCut
+		}
+	default:
+		if ok {
+			return OutcomeSuccess
+		}
+		return OutcomeDislodged
+	}
+}
@@ -0,0 +1,108 @@
+package diplo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RuleSet is a selection of variant and house rules for a game.
+//
+// The zero value is the standard rules, with convoy paradoxes decided
+// by [ParadoxSzykman].
+type RuleSet struct {
+	// Paradox decides convoy paradoxes.
+	Paradox ParadoxRule `json:",omitempty"`
+	// BuildAnywhere allows builds on any supply center the building country
+	// controls, not only its home centers.
+	BuildAnywhere bool `json:",omitempty"`
+	// NoCoastNeeded lets fleets move, retreat, and build on provinces with
+	// several coasts without naming one; the first coast it can reach is used.
+	NoCoastNeeded bool `json:",omitempty"`
+	// ConvoyViaCoasts lets fleets in coastal provinces convoy armies, like
+	// the canals of Ancient Mediterranean.
+	ConvoyViaCoasts bool `json:",omitempty"`
+	// SelfSupportCut lets a country's attack cut the support of its own unit.
+	SelfSupportCut bool `json:",omitempty"`
+	// VictoryCenters is how many supply centers a country needs to win.
+	// If zero, more than half of the board's supply centers are needed.
+	VictoryCenters int `json:",omitempty"`
+	// DisbandOnNMR is a house rule that disbands the units of a country that
+	// gives no orders in a move phase. The units still hold for that phase,
+	// defending their provinces and able to be dislodged, and are removed
+	// once it is adjudicated. Without it, they hold and remain.
+	//
+	// Unordered retreats and adjustments are not affected: dislodged units
+	// without orders always disband, and missing disbands are taken by civil
+	// disorder (see [Game.CivilDisorder]).
+	DisbandOnNMR bool `json:",omitempty"`
+}
+
+// ParadoxRule decides the outcome of a convoy paradox: orders that depend on
+// each other through a convoy, such that either no result or more than one
+// result is consistent with the rules.
+//
+// Paradoxes with no convoy are circular movement, in which every move succeeds
+// under all rules.
+type ParadoxRule int
+
+const (
+	// ParadoxSzykman makes every army convoyed through a paradox hold, with no
+	// effect on other units: it neither moves nor cuts support.
+	ParadoxSzykman ParadoxRule = iota
+	// Paradox1982 follows the 1982 rulebook: a convoyed army never cuts the
+	// support of a unit supporting an attack on one of the fleets convoying it.
+	// Paradoxes that remain are decided as with [ParadoxSzykman].
+	Paradox1982
+	// Paradox2000 follows the 2000 rulebook: every convoy caught in a
+	// paradox fails.
+	Paradox2000
+	// ParadoxDPTG follows the Diplomacy Player's Technical Guide: when a
+	// paradox occurs, the armies convoyed through it do not cut the support
+	// of units supporting an attack on their convoys. Paradoxes that remain
+	// are decided as with [ParadoxSzykman].
+	ParadoxDPTG
+)
+
+var paradoxRuleNames = [...]string{
+	ParadoxSzykman: "Szykman",
+	Paradox1982:    "1982",
+	Paradox2000:    "2000",
+	ParadoxDPTG:    "DPTG",
+}
+
+// String is the name of the rule without its "Paradox" prefix, e.g. "1982".
+func (r ParadoxRule) String() string {
+	if r < 0 || int(r) >= len(paradoxRuleNames) {
+		return fmt.Sprintf("ParadoxRule(%d)", int(r))
+	}
+	return paradoxRuleNames[r]
+}
+
+// MarshalText writes the rule by name, as given by [ParadoxRule.String].
+func (r ParadoxRule) MarshalText() ([]byte, error) {
+	if r < 0 || int(r) >= len(paradoxRuleNames) {
+		return nil, fmt.Errorf("invalid paradox rule %d", int(r))
+	}
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText reads the rule by name, as accepted by [ParseParadoxRule].
+func (r *ParadoxRule) UnmarshalText(text []byte) error {
+	rule, ok := ParseParadoxRule(string(text))
+	if !ok {
+		return fmt.Errorf("unknown paradox rule %s", text)
+	}
+	*r = rule
+	return nil
+}
+
+// ParseParadoxRule interprets the name of a paradox rule, as given by
+// [ParadoxRule.String]. Case is ignored.
+func ParseParadoxRule(name string) (ParadoxRule, bool) {
+	for r, n := range paradoxRuleNames {
+		if strings.EqualFold(n, name) {
+			return ParadoxRule(r), true
+		}
+	}
+	return 0, false
+}
@@ -1,10 +1,52 @@
 package diplo
 
+import (
+	_ "embed"
+	"encoding/json"
+)
+
 // StandardBoard is used for a standard game of Diplomacy.
 var StandardBoard *Board
 
+// standardCountries is how the standard countries are shown.
+var standardCountries = map[string]CountryInfo{
+	"Austria": {"Austrian", "#C62828"},
+	"England": {"English", "#1A237E"},
+	"France":  {"French", "#4FC3F7"},
+	"Germany": {"German", "#424242"},
+	"Italy":   {"Italian", "#2E7D32"},
+	"Russia":  {"Russian", "#7B1FA2"},
+	"Turkey":  {"Turkish", "#F9A825"},
+}
+
 func init() {
-	standardBuilder := Builder{
+	StandardBoard = mustBuildBundled(standardBuilder())
+	mustRegisterVariant(Variant{
+		Name:        "Standard",
+		Description: "The standard game of Diplomacy.",
+		Board:       StandardBoard,
+		Countries:   standardCountries,
+	})
+}
+
+// standardGeometryJSON is a schematic layout of the standard board, with each
+// province's outline drawn around where it lies on the map of Europe.
+//
+//go:embed standard_geometry.json
+var standardGeometryJSON []byte
+
+func standardGeometry() *Geometry {
+	var g Geometry
+	if err := json.Unmarshal(standardGeometryJSON, &g); err != nil {
+		panic(err)
+	}
+	return &g
+}
+
+// standardBuilder gives the standard board's definition. Each call returns a
+// fresh Builder, since building one modifies it.
+func standardBuilder() Builder {
+	return Builder{
 		Countries: []string{
 			"Austria",
 			"England",
@@ -132,7 +174,7 @@ func init() {
 			},
 			{
 				Name:          "English Channel",
-				Abbreviations: []string{"ENG"},
+				Abbreviations: []string{"ENG", "ECH"},
 				Terrain:       Water,
 			},
 			{
@@ -158,17 +200,19 @@ func init() {
 			},
 			{
 				Name:          "Gulf of Bothnia",
-				Abbreviations: []string{"BOT"},
+				Abbreviations: []string{"BOT", "GOB"},
 				Terrain:       Water,
 			},
 			{
 				Name:          "Gulf of Lyon",
 				Abbreviations: []string{"LYO", "GOL"},
+				Aliases:       []string{"Gulf of Lyons"},
 				Terrain:       Water,
 			},
 			{
 				Name:          "Helgoland Bight",
 				Abbreviations: []string{"HEL"},
+				Aliases:       []string{"Heligoland Bight"},
 				Terrain:       Water,
 			},
 			{
@@ -306,6 +350,7 @@ func init() {
 			{
 				Name:          "Rumania",
 				Abbreviations: []string{"Rum"},
+				Aliases:       []string{"Romania"},
 				Terrain:       Coastal,
 				Center:        true,
 			},
@@ -347,6 +392,7 @@ func init() {
 			{
 				Name:          "St. Petersburg",
 				Abbreviations: []string{"StP"},
+				Aliases:       []string{"Saint Petersburg"},
 				Terrain:       Coastal,
 				Coasts:        []string{"NC", "SC"},
 				Country:       "Russia",
@@ -880,35 +926,58 @@ func init() {
 				To:   "Yor",
 			},
 		},
+		Units: []BuilderUnit{
+			{"Austria", "Army", "Vienna", ""},
+			{"Austria", "Army", "Budapest", ""},
+			{"Austria", "Fleet", "Trieste", ""},
+			{"England", "Fleet", "London", ""},
+			{"England", "Fleet", "Edinburgh", ""},
+			{"England", "Army", "Liverpool", ""},
+			{"France", "Army", "Paris", ""},
+			{"France", "Army", "Marseilles", ""},
+			{"France", "Fleet", "Brest", ""},
+			{"Germany", "Army", "Berlin", ""},
+			{"Germany", "Army", "Munich", ""},
+			{"Germany", "Fleet", "Kiel", ""},
+			{"Italy", "Army", "Rome", ""},
+			{"Italy", "Army", "Venice", ""},
+			{"Italy", "Fleet", "Naples", ""},
+			{"Russia", "Army", "Moscow", ""},
+			{"Russia", "Army", "Warsaw", ""},
+			{"Russia", "Fleet", "Sevastopol", ""},
+			{"Russia", "Fleet", "St. Petersburg", "SC"},
+			{"Turkey", "Army", "Constantinople", ""},
+			{"Turkey", "Army", "Smyrna", ""},
+			{"Turkey", "Fleet", "Ankara", ""},
+		},
+		Geometry: standardGeometry(),
 	}
-	b, err := standardBuilder.Build()
-	if err != nil {
-		panic(err)
-	}
-	StandardBoard = b
 }
 
 // StandardGameSetup sets up the board for a standard game
 // by placing the units on the board.
 //
 // It should be called directly after creating a game object
-// with [NewGame].
+// with [NewGame]. The board's starting units are placed as with
+// [NewStartingGame], so it also works on boards built from the
+// standard one, like [FleetRomeBoard].
 //
 // Prefer to use [StandardGame].
 //
-// Panics if not used on a game with the standard board.
+// Panics if the board's starting position is not valid.
 func StandardGameSetup(g *Game) {
-	if g.board != StandardBoard {
-		panic("standard board not used")
+	if err := g.placeStartingUnits(); err != nil {
+		panic(err)
 	}
-	g.SetUnit(g.board.Province("London"), "", Fleet, "England")
-	// TODO
 }
 
 // StandardGame returns the Spring 1901 game state for a
 // standard game of Diplomacy.
 func StandardGame() *Game {
-	g := NewGame(StandardBoard)
-	StandardGameSetup(g)
+	g, err := NewStartingGame(StandardBoard)
+	if err != nil {
+		// The standard board is checked when the package is loaded.
+		panic(err)
+	}
 	return g
 }
@@ -0,0 +1,89 @@
+package diplo
+
+import "testing"
+
+// standardUnits is the starting position published in the rulebook.
+var standardUnits = []struct {
+	country  string
+	unit     Unit
+	province string
+	coast    string
+}{
+	{"Austria", Army, "Vie", ""},
+	{"Austria", Army, "Bud", ""},
+	{"Austria", Fleet, "Tri", ""},
+	{"England", Fleet, "Lon", ""},
+	{"England", Fleet, "Edi", ""},
+	{"England", Army, "Lvp", ""},
+	{"France", Fleet, "Bre", ""},
+	{"France", Army, "Par", ""},
+	{"France", Army, "Mar", ""},
+	{"Germany", Fleet, "Kie", ""},
+	{"Germany", Army, "Ber", ""},
+	{"Germany", Army, "Mun", ""},
+	{"Italy", Fleet, "Nap", ""},
+	{"Italy", Army, "Rom", ""},
+	{"Italy", Army, "Ven", ""},
+	{"Russia", Army, "Mos", ""},
+	{"Russia", Army, "War", ""},
+	{"Russia", Fleet, "Sev", ""},
+	{"Russia", Fleet, "StP", "SC"},
+	{"Turkey", Fleet, "Ank", ""},
+	{"Turkey", Army, "Con", ""},
+	{"Turkey", Army, "Smy", ""},
+}
+
+func TestStandardGame(t *testing.T) {
+	g := StandardGame()
+	for _, want := range standardUnits {
+		p := StandardBoard.ParseProvince(want.province)[0]
+		u := g.Unit(p)
+		if u == nil {
+			t.Errorf("no unit in %s", want.province)
+			continue
+		}
+		coast, _ := u.Coast()
+		if u.Country() != want.country || u.Unit() != want.unit || coast != want.coast {
+			t.Errorf("%s: got %s %v on %q, want %s %v on %q",
+				want.province, u.Country(), u.Unit(), coast, want.country, want.unit, want.coast)
+		}
+	}
+	if n := len(g.units); n != len(standardUnits) {
+		t.Errorf("StandardGame has %d units, want %d", n, len(standardUnits))
+	}
+	if err := g.ValidateSetup(); err != nil {
+		t.Errorf("ValidateSetup: %v", err)
+	}
+}
+
+func TestValidateSetupStartingUnits(t *testing.T) {
+	stp := StandardBoard.ParseProvince("StP")[0]
+	tri := StandardBoard.ParseProvince("Tri")[0]
+	tests := []struct {
+		name  string
+		setup func(g *Game) error
+	}{
+		{"wrong coast", func(g *Game) error {
+			return g.SetUnit(stp, "NC", Fleet, "Russia")
+		}},
+		{"wrong type", func(g *Game) error {
+			return g.SetUnit(tri, "", Army, "Austria")
+		}},
+		{"missing unit", func(g *Game) error {
+			g.RemoveUnit(tri)
+			return nil
+		}},
+		{"extra unit", func(g *Game) error {
+			return g.SetUnit(StandardBoard.ParseProvince("Gal")[0], "", Army, "Austria")
+		}},
+	}
+	for _, tt := range tests {
+		g := StandardGame()
+		if err := tt.setup(g); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if err := g.ValidateSetup(); err == nil {
+			t.Errorf("%s: ValidateSetup succeeded", tt.name)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package diplo
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Colors of the parts of the map that do not belong to a country.
+const (
+	svgLand       = "#EDE3C8"
+	svgWater      = "#AFCFE8"
+	svgImpassable = "#BDBDBD"
+	svgBorder     = "#5D4037"
+	svgFailed     = "#D50000"
+	svgBuilt      = "#2E7D32"
+)
+
+// svgDislodgedOffset is how far a dislodged unit is drawn from where it
+// would otherwise be, so as not to cover the unit that dislodged it.
+var svgDislodgedOffset = Point{14, 12}
+
+// svgMap draws a game as an SVG picture.
+type svgMap struct {
+	game     *Game
+	geometry *Geometry
+	variant  Variant
+	sb       strings.Builder
+}
+
+func newSVGMap(g *Game) (*svgMap, error) {
+	if g.board.geometry == nil {
+		return nil, errors.New("board has no geometry")
+	}
+	variant, _ := VariantOf(g.board)
+	return &svgMap{
+		game:     g,
+		geometry: g.board.geometry,
+		variant:  variant,
+	}, nil
+}
+
+// WriteSVG draws the game as an SVG picture, using the geometry of its board
+// (see [Builder.Geometry]). Supply centers are shaded by the country that
+// controls them, in the colors of the board's variant (see [Variant]).
+// Fleets on named coasts are drawn at those coasts, and in retreat phases,
+// dislodged units are drawn faded and offset from the unit that dislodged
+// them.
+//
+// It is an error if the board has no geometry.
+func (g *Game) WriteSVG(w io.Writer) error {
+	s, err := newSVGMap(g)
+	if err != nil {
+		return err
+	}
+	s.start()
+	s.provinces()
+	s.units()
+	return s.end(w)
+}
+
+// WriteSVG draws the arena's game as [Game.WriteSVG] does, with the orders
+// given so far on top: moves and retreats as arrows, convoyed moves as dashed
+// arrows through the convoying fleets (see [Game.ConvoyChains]), supports as
+// lines to the supported unit or to the middle of the supported move, holds
+// and convoys as rings, and builds and disbands as markers.
+//
+// Orders that failed in adjudication are drawn dashed with a red cross, and
+// illegal orders are drawn dotted in red. Each order is titled with its
+// outcome, so call it after [Arena.Go] to see the result of a phase.
+func (a *Arena) WriteSVG(w io.Writer) error {
+	s, err := newSVGMap(a.game)
+	if err != nil {
+		return err
+	}
+	s.start()
+	s.provinces()
+	s.units()
+	s.orders(a)
+	return s.end(w)
+}
+
+func svgNumber(n float64) string {
+	return strconv.FormatFloat(math.Round(n*10)/10, 'f', -1, 64)
+}
+
+func svgPoints(points []Point) string {
+	ps := make([]string, len(points))
+	for i, p := range points {
+		ps[i] = svgNumber(p[0]) + "," + svgNumber(p[1])
+	}
+	return strings.Join(ps, " ")
+}
+
+func (s *svgMap) start() {
+	w, h := svgNumber(s.geometry.Width), svgNumber(s.geometry.Height)
+	fmt.Fprintf(&s.sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %[1]s %[2]s" font-family="sans-serif">`+"\n", w, h)
+	fmt.Fprintf(&s.sb, `<rect width="%s" height="%s" fill="%s"/>`+"\n", w, h, svgLand)
+}
+
+func (s *svgMap) end(w io.Writer) error {
+	s.sb.WriteString("</svg>\n")
+	_, err := io.WriteString(w, s.sb.String())
+	return err
+}
+
+// provinces draws each province's outline, shaded by terrain and by the
+// country controlling it, with its supply center and abbreviation.
+func (s *svgMap) provinces() {
+	for _, outline := range s.geometry.Impassable {
+		fmt.Fprintf(&s.sb, `<polygon points="%s" fill="%s" stroke="%s" stroke-width="1"/>`+"\n",
+			svgPoints(outline), svgImpassable, svgBorder)
+	}
+	for _, p := range s.game.board.provinces {
+		fill, opacity := svgLand, "1"
+		if p.terrain == Water {
+			fill = svgWater
+		} else if country, ok := s.game.Center(p); ok {
+			fill, opacity = s.variant.Country(country).Color, "0.45"
+		}
+		fmt.Fprintf(&s.sb, `<polygon points="%s" fill="%s" fill-opacity="%s" stroke="%s" stroke-width="1"><title>%s</title></polygon>`+"\n",
+			svgPoints(s.geometry.Provinces[p.name].Polygon), fill, opacity, svgBorder, html.EscapeString(p.name))
+	}
+	for _, p := range s.game.board.provinces {
+		at := s.geometry.Provinces[p.name].Unit
+		if p.center {
+			fill := "#FFFFFF"
+			if country, ok := s.game.Center(p); ok {
+				fill = s.variant.Country(country).Color
+			}
+			fmt.Fprintf(&s.sb, `<circle cx="%s" cy="%s" r="4" fill="%s" stroke="#212121" stroke-width="1"/>`+"\n",
+				svgNumber(at[0]+12), svgNumber(at[1]-12), fill)
+		}
+		fmt.Fprintf(&s.sb, `<text x="%s" y="%s" font-size="10" text-anchor="middle" fill="#212121">%s</text>`+"\n",
+			svgNumber(at[0]), svgNumber(at[1]+20), html.EscapeString(p.abbrs[0]))
+	}
+}
+
+// units draws each unit, then each dislodged unit.
+func (s *svgMap) units() {
+	for _, p := range s.game.board.provinces {
+		if u := s.game.Unit(p); u != nil {
+			s.unit(u, s.geometry.unitPoint(p, u.coast), false)
+		}
+	}
+	if !s.game.phase.Retreat() {
+		return
+	}
+	for _, p := range s.game.board.provinces {
+		if u := s.game.DislodgedUnit(p); u != nil {
+			at := s.geometry.unitPoint(p, u.coast)
+			at = Point{at[0] + svgDislodgedOffset[0], at[1] + svgDislodgedOffset[1]}
+			s.unit(u, at, true)
+		}
+	}
+}
+
+// unit draws a unit at a point: an Army as a circle and a Fleet as a
+// rounded rectangle, in the color of its country. Dislodged units are faded
+// and outlined in red.
+func (s *svgMap) unit(u *Occupancy, at Point, dislodged bool) {
+	stroke, extra := "#212121", ""
+	if dislodged {
+		stroke, extra = "#D50000", ` opacity="0.75" stroke-dasharray="3,2"`
+	}
+	name := u.province.abbrs[0]
+	if u.coast != "" {
+		name += "(" + u.coast + ")"
+	}
+	title := fmt.Sprintf("%s %s %s", s.variant.Country(u.country).Adjective, u.unit, name)
+	if dislodged {
+		title += " (dislodged)"
+	}
+	fmt.Fprintf(&s.sb, `<g%s><title>%s</title>`, extra, html.EscapeString(title))
+	x, y := at[0], at[1]
+	fill := s.variant.Country(u.country).Color
+	if u.unit == Fleet {
+		fmt.Fprintf(&s.sb, `<rect x="%s" y="%s" width="20" height="13" rx="3" fill="%s" stroke="%s" stroke-width="1.5"/>`,
+			svgNumber(x-10), svgNumber(y-6.5), fill, stroke)
+	} else {
+		fmt.Fprintf(&s.sb, `<circle cx="%s" cy="%s" r="8" fill="%s" stroke="%s" stroke-width="1.5"/>`,
+			svgNumber(x), svgNumber(y), fill, stroke)
+	}
+	fmt.Fprintf(&s.sb, `<text x="%s" y="%s" font-size="9" font-weight="bold" text-anchor="middle" fill="#FFFFFF">%s</text></g>`+"\n",
+		svgNumber(x), svgNumber(y+3), u.unit.String()[:1])
+}
+
+// svgStyle is how an order is drawn, by its outcome.
+type svgStyle struct {
+	color  string
+	dash   string // stroke-dasharray, if any
+	failed bool   // failed in adjudication, marked with a cross
+}
+
+func (s *svgMap) style(country string, outcome Outcome) svgStyle {
+	switch outcome {
+	case OutcomeSuccess:
+		return svgStyle{color: s.variant.Country(country).Color}
+	case OutcomeNoConvoy, OutcomeDislodged, OutcomeCut,
+		OutcomeWeak, OutcomeStandoff, OutcomeOverpowered:
+		return svgStyle{color: s.variant.Country(country).Color, dash: "6,4", failed: true}
+	default:
+		return svgStyle{color: svgFailed, dash: "2,3"}
+	}
+}
+
+func (st svgStyle) attrs(width float64) string {
+	a := fmt.Sprintf(`stroke="%s" stroke-width="%s" fill="none"`, st.color, svgNumber(width))
+	if st.dash != "" {
+		a += fmt.Sprintf(` stroke-dasharray="%s"`, st.dash)
+	}
+	return a
+}
+
+// orders draws each country's orders, in the order of their text.
+func (s *svgMap) orders(a *Arena) {
+	for _, country := range s.game.board.countries {
+		outcomes := a.Outcomes(country)
+		orders := slices.SortedFunc(maps.Keys(outcomes), func(x, y Order) int {
+			return strings.Compare(x.Format(s.game, StyleWebDip), y.Format(s.game, StyleWebDip))
+		})
+		for _, o := range orders {
+			fmt.Fprintf(&s.sb, `<g><title>%s: %s (%s)</title>`,
+				html.EscapeString(country), html.EscapeString(o.Format(s.game, StyleWebDip)), outcomes[o])
+			s.order(a, country, o, s.style(country, outcomes[o]))
+			s.sb.WriteString("</g>\n")
+		}
+	}
+}
+
+func (s *svgMap) order(a *Arena, country string, o Order, st svgStyle) {
+	switch o.Kind() {
+	case HoldDisband:
+		at, ok := s.orderUnit(o.Unit)
+		if !ok {
+			return
+		}
+		if s.game.phase.Move() {
+			s.ring(at, 13, st, 2)
+		} else {
+			s.cross(at, 9, svgFailed)
+		}
+	case MoveRetreat:
+		from, ok := s.orderUnit(o.Unit)
+		if !ok {
+			return
+		}
+		to := s.geometry.unitPoint(o.Target, o.TargetCoast)
+		path := []Point{from}
+		if s.game.phase.Move() {
+			path = append(path, s.convoyPath(a, country, o)...)
+		}
+		if len(path) > 1 && st.dash == "" {
+			st.dash = "8,4"
+		}
+		s.arrow(append(path, to), st)
+	case SupportHold:
+		from, ok := s.orderUnit(o.Unit)
+		if !ok {
+			return
+		}
+		to := s.geometry.unitPoint(o.Recipient, s.unitCoast(o.Recipient))
+		s.line(from, to, 13, st)
+		s.ring(to, 13, st, 1.5)
+	case SupportMove:
+		from, ok := s.orderUnit(o.Unit)
+		if !ok {
+			return
+		}
+		mover := s.geometry.unitPoint(o.Recipient, s.unitCoast(o.Recipient))
+		target := s.geometry.unitPoint(o.Target, o.TargetCoast)
+		mid := Point{(mover[0] + target[0]) / 2, (mover[1] + target[1]) / 2}
+		s.line(from, mid, 0, st)
+		fmt.Fprintf(&s.sb, `<circle cx="%s" cy="%s" r="3" fill="%s"/>`,
+			svgNumber(mid[0]), svgNumber(mid[1]), st.color)
+	case Convoy:
+		at, ok := s.orderUnit(o.Unit)
+		if !ok {
+			return
+		}
+		if st.dash == "" {
+			st.dash = "4,3"
+		}
+		s.ring(at, 13, st, 2)
+	case Build:
+		at := s.geometry.unitPoint(o.Target, o.TargetCoast)
+		if st.color == svgFailed {
+			s.ring(at, 13, st, 2)
+			return
+		}
+		s.unit(&Occupancy{o.Target, o.TargetCoast, o.Build, country}, at, false)
+		st.color = svgBuilt
+		s.ring(at, 13, st, 2)
+	}
+	if st.failed {
+		s.failedMark(s.orderEnd(o))
+	}
+}
+
+// orderUnit is where the unit given an order is drawn: the dislodged unit in
+// retreat phases.
+func (s *svgMap) orderUnit(p *Province) (Point, bool) {
+	if p == nil {
+		return Point{}, false
+	}
+	if s.game.phase.Retreat() {
+		if u := s.game.DislodgedUnit(p); u != nil {
+			at := s.geometry.unitPoint(p, u.coast)
+			return Point{at[0] + svgDislodgedOffset[0], at[1] + svgDislodgedOffset[1]}, true
+		}
+	}
+	return s.geometry.unitPoint(p, s.unitCoast(p)), true
+}
+
+func (s *svgMap) unitCoast(p *Province) string {
+	if u := s.game.Unit(p); u != nil {
+		return u.coast
+	}
+	return ""
+}
+
+// orderEnd is where a failed order is marked.
+func (s *svgMap) orderEnd(o Order) Point {
+	switch o.Kind() {
+	case MoveRetreat:
+		return s.geometry.unitPoint(o.Target, o.TargetCoast)
+	case SupportMove:
+		mover := s.geometry.unitPoint(o.Recipient, s.unitCoast(o.Recipient))
+		target := s.geometry.unitPoint(o.Target, o.TargetCoast)
+		return Point{(mover[0] + target[0]) / 2, (mover[1] + target[1]) / 2}
+	default:
+		at, _ := s.orderUnit(o.Unit)
+		return at
+	}
+}
+
+// convoyPath is where the fleets convoying a move are drawn, if it goes by
+// convoy: along the first route whose fleets were all ordered to convoy it,
+// or else the first route.
+func (s *svgMap) convoyPath(a *Arena, country string, o Order) []Point {
+	u := s.game.Unit(o.Unit)
+	if u == nil || u.unit != Army || !o.ViaConvoy && s.game.HasNeighbor(u, o.Target) {
+		return nil
+	}
+	chains := s.game.ConvoyChains(o.Unit, o.Target)
+	if len(chains) == 0 {
+		return nil
+	}
+	chain := chains[0]
+	for _, c := range chains {
+		if !slices.ContainsFunc(c, func(p *Province) bool {
+			f := s.game.Unit(p)
+			order, _, ok := a.Unit(f)
+			return !ok || order != OrderConvoy(p, o.Unit, o.Target)
+		}) {
+			chain = c
+			break
+		}
+	}
+	path := make([]Point, len(chain))
+	for i, p := range chain {
+		path[i] = s.geometry.unitPoint(p, "")
+	}
+	return path
+}
+
+// shorten moves the ends of a line inward, to leave room for unit glyphs.
+func shorten(from, to Point, start, end float64) (Point, Point) {
+	dx, dy := to[0]-from[0], to[1]-from[1]
+	d := math.Hypot(dx, dy)
+	if d <= start+end {
+		return from, to
+	}
+	ux, uy := dx/d, dy/d
+	return Point{from[0] + ux*start, from[1] + uy*start}, Point{to[0] - ux*end, to[1] - uy*end}
+}
+
+func (s *svgMap) line(from, to Point, end float64, st svgStyle) {
+	from, to = shorten(from, to, 9, end)
+	fmt.Fprintf(&s.sb, `<line x1="%s" y1="%s" x2="%s" y2="%s" %s/>`,
+		svgNumber(from[0]), svgNumber(from[1]), svgNumber(to[0]), svgNumber(to[1]), st.attrs(2))
+}
+
+// arrow draws a path through points with an arrowhead at its end.
+func (s *svgMap) arrow(path []Point, st svgStyle) {
+	n := len(path)
+	path = slices.Clone(path)
+	path[0], _ = shorten(path[0], path[1], 9, 0)
+	_, path[n-1] = shorten(path[n-2], path[n-1], 0, 11)
+	fmt.Fprintf(&s.sb, `<polyline points="%s" %s stroke-linejoin="round"/>`, svgPoints(path), st.attrs(3))
+	from, tip := path[n-2], path[n-1]
+	d := math.Hypot(tip[0]-from[0], tip[1]-from[1])
+	if d == 0 {
+		return
+	}
+	ux, uy := (tip[0]-from[0])/d, (tip[1]-from[1])/d
+	head := []Point{
+		{tip[0] + ux*4, tip[1] + uy*4},
+		{tip[0] - ux*6 - uy*6, tip[1] - uy*6 + ux*6},
+		{tip[0] - ux*6 + uy*6, tip[1] - uy*6 - ux*6},
+	}
+	fmt.Fprintf(&s.sb, `<polygon points="%s" fill="%s"/>`, svgPoints(head), st.color)
+}
+
+func (s *svgMap) ring(at Point, r float64, st svgStyle, width float64) {
+	fmt.Fprintf(&s.sb, `<circle cx="%s" cy="%s" r="%s" %s/>`,
+		svgNumber(at[0]), svgNumber(at[1]), svgNumber(r), st.attrs(width))
+}
+
+func (s *svgMap) cross(at Point, size float64, color string) {
+	fmt.Fprintf(&s.sb, `<path d="M%s %sL%s %sM%s %sL%s %s" stroke="%s" stroke-width="3"/>`,
+		svgNumber(at[0]-size), svgNumber(at[1]-size), svgNumber(at[0]+size), svgNumber(at[1]+size),
+		svgNumber(at[0]-size), svgNumber(at[1]+size), svgNumber(at[0]+size), svgNumber(at[1]-size), color)
+}
+
+// failedMark marks an order that failed in adjudication with a small cross.
+func (s *svgMap) failedMark(at Point) {
+	s.cross(at, 5, svgFailed)
+}
@@ -0,0 +1,192 @@
+package diplo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// svgNode is an element of a parsed SVG picture.
+type svgNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []svgNode  `xml:",any"`
+	Text     string     `xml:",chardata"`
+}
+
+func (n *svgNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (n *svgNode) number(t *testing.T, name string) float64 {
+	t.Helper()
+	f, err := strconv.ParseFloat(n.attr(name), 64)
+	if err != nil {
+		t.Fatalf("<%s> %s: %v", n.XMLName.Local, name, err)
+	}
+	return f
+}
+
+// child gets the first child element with a name, or nil.
+func (n *svgNode) child(name string) *svgNode {
+	for i := range n.Children {
+		if n.Children[i].XMLName.Local == name {
+			return &n.Children[i]
+		}
+	}
+	return nil
+}
+
+// group gets the group of elements with a title, or nil.
+func (n *svgNode) group(title string) *svgNode {
+	for i := range n.Children {
+		c := &n.Children[i]
+		if c.XMLName.Local == "g" && c.child("title") != nil && c.child("title").Text == title {
+			return c
+		}
+	}
+	return nil
+}
+
+// parseSVG checks that a picture is well-formed XML with an svg root.
+func parseSVG(t *testing.T, data []byte) *svgNode {
+	t.Helper()
+	var root svgNode
+	if err := xml.Unmarshal(data, &root); err != nil {
+		t.Fatalf("picture is not XML: %v\n%s", err, data)
+	}
+	if root.XMLName.Local != "svg" {
+		t.Fatalf("root element is %s, want svg", root.XMLName.Local)
+	}
+	return &root
+}
+
+// fleetAt checks that a Fleet is drawn centered on a point.
+func fleetAt(t *testing.T, g *svgNode, at Point) {
+	t.Helper()
+	rect := g.child("rect")
+	if rect == nil {
+		t.Fatal("fleet is not drawn as a rect")
+	}
+	x, y := rect.number(t, "x")+10, rect.number(t, "y")+6.5
+	if x != at[0] || y != at[1] {
+		t.Errorf("fleet drawn at [%v %v], want %v", x, y, at)
+	}
+}
+
+func TestWriteSVGSplitCoast(t *testing.T) {
+	var buf bytes.Buffer
+	if err := StandardGame().WriteSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	root := parseSVG(t, buf.Bytes())
+	stp := StandardBoard.Province("St. Petersburg")
+	anchor := StandardBoard.geometry.unitPoint(stp, "SC")
+	if anchor == StandardBoard.geometry.Provinces[stp.name].Unit {
+		t.Fatal("St. Petersburg's south coast has no anchor of its own")
+	}
+	g := root.group("Russian Fleet StP(SC)")
+	if g == nil {
+		t.Fatal("no Russian Fleet StP(SC)")
+	}
+	fleetAt(t, g, anchor)
+}
+
+func TestWriteSVGDislodged(t *testing.T) {
+	g, err := ParseNotation(StandardBoard, "F1901R E:FNTH;T:ABul,FBla;D:FBul/EC=E<Con")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := g.WriteSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	root := parseSVG(t, buf.Bytes())
+	unit := root.group("English Fleet Bul(EC) (dislodged)")
+	if unit == nil {
+		t.Fatal("no dislodged English Fleet Bul(EC)")
+	}
+	if unit.attr("stroke-dasharray") == "" || unit.attr("opacity") == "" {
+		t.Error("dislodged unit is not faded and dashed")
+	}
+	at := StandardBoard.geometry.unitPoint(StandardBoard.Province("Bulgaria"), "EC")
+	fleetAt(t, unit, Point{at[0] + svgDislodgedOffset[0], at[1] + svgDislodgedOffset[1]})
+	if root.group("Turkish Army Bul") == nil {
+		t.Error("no Turkish Army Bul")
+	}
+}
+
+func TestArenaWriteSVGOutcomes(t *testing.T) {
+	g, err := ParseNotation(StandardBoard, "S1901M F:APar;G:AMun;I:AVen;SC:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := g.Arena()
+	for _, o := range []struct{ country, text string }{
+		{"France", "A Par - Bur"},
+		{"Germany", "A Mun - Bur"},
+		{"Italy", "A Ven - Pie"},
+	} {
+		if _, err := a.Add(o.country, mustParseOrder(t, g, o.country, o.text)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := a.WriteSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	root := parseSVG(t, buf.Bytes())
+
+	failed := root.group("France: A Par - Bur (Standoff)")
+	if failed == nil {
+		t.Fatal("no failed move drawn")
+	}
+	if line := failed.child("polyline"); line == nil || line.attr("stroke-dasharray") != "6,4" {
+		t.Error("failed move is not drawn dashed")
+	}
+	if cross := failed.child("path"); cross == nil || cross.attr("stroke") != svgFailed ||
+		strings.Count(cross.attr("d"), "M") != 2 {
+		t.Error("failed move is not marked with a cross")
+	}
+
+	moved := root.group("Italy: A Ven - Pie (Success)")
+	if moved == nil {
+		t.Fatal("no successful move drawn")
+	}
+	if line := moved.child("polyline"); line == nil || line.attr("stroke-dasharray") != "" {
+		t.Error("successful move is not drawn solid")
+	}
+	if moved.child("path") != nil {
+		t.Error("successful move is marked with a cross")
+	}
+}
+
+func TestWriteSVGNoGeometry(t *testing.T) {
+	b := standardBuilder()
+	b.Geometry = nil
+	board, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewStartingGame(board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := g.WriteSVG(&buf); err == nil {
+		t.Error("Game.WriteSVG succeeded on a board without geometry")
+	}
+	if err := g.Arena().WriteSVG(&buf); err == nil {
+		t.Error("Arena.WriteSVG succeeded on a board without geometry")
+	}
+	if buf.Len() > 0 {
+		t.Errorf("wrote %d bytes", buf.Len())
+	}
+}
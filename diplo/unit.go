@@ -1,8 +1,31 @@
 package diplo
 
+import "strings"
+
 type Unit int
 
 const (
 	Army Unit = iota
 	Fleet
 )
+
+// String is the name of the unit type, "Army" or "Fleet".
+func (u Unit) String() string {
+	if u == Fleet {
+		return "Fleet"
+	}
+	return "Army"
+}
+
+// ParseUnit interprets a unit type, by its name or its initial (A or F).
+// Case is ignored.
+func ParseUnit(name string) (Unit, bool) {
+	switch strings.ToLower(name) {
+	case "a", "army":
+		return Army, true
+	case "f", "fleet":
+		return Fleet, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,182 @@
+package diplo
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Variant is a way of playing Diplomacy: a board, how a game on it is set up,
+// the rules it is played with, and how its countries are shown.
+type Variant struct {
+	// Name identifies the variant, like "Fleet Rome". Names are case-insensitive.
+	Name string
+	// Description is a sentence or two on how the variant differs from the
+	// standard game.
+	Description string
+	// Board is the board the variant is played on.
+	Board *Board
+	// Setup places the units of a new game on the board, for boards set up
+	// by hand. If nil, the board's starting units are placed (see
+	// [NewStartingGame]).
+	Setup func(*Game)
+	// Rules is the rules the variant is played with by default, including how
+	// many supply centers are needed to win.
+	Rules RuleSet
+	// Countries is how each of the board's countries is shown.
+	Countries map[string]CountryInfo
+}
+
+// CountryInfo is how a country is shown.
+type CountryInfo struct {
+	// Adjective describes the country's units, like "French".
+	Adjective string
+	// Color is the color of the country's units and centers, in hexadecimal
+	// like "#1E88E5".
+	Color string
+}
+
+// NewGame returns the first game state of the variant, set up and with its
+// rules.
+func (v Variant) NewGame() (*Game, error) {
+	var g *Game
+	if v.Setup != nil {
+		g = NewGame(v.Board)
+		v.Setup(g)
+	} else {
+		var err error
+		if g, err = NewStartingGame(v.Board); err != nil {
+			return nil, fmt.Errorf("variant %s: %w", v.Name, err)
+		}
+	}
+	g.SetRules(v.Rules)
+	return g, nil
+}
+
+// Country gets how a country is shown, defaulting to its name as the
+// adjective and gray as the color.
+func (v Variant) Country(country string) CountryInfo {
+	info := v.Countries[country]
+	if info.Adjective == "" {
+		info.Adjective = country
+	}
+	if info.Color == "" {
+		info.Color = "#9E9E9E"
+	}
+	return info
+}
+
+var (
+	variantsMu sync.RWMutex
+	variants   = make(map[string]Variant) // by lowercase name
+)
+
+// RegisterVariant makes a variant available by name. Its board is registered
+// by the same name (see [RegisterBoard]) unless it already has one, so that
+// saved games refer to the board by a stable name.
+//
+// The bundled variants are "Standard" and "Fleet Rome". Boards for other
+// variants can be loaded with [LoadBoard] and registered here.
+func RegisterVariant(v Variant) error {
+	if v.Name == "" || v.Board == nil {
+		return errors.New("variant name and board required")
+	}
+	for c := range v.Countries {
+		if err := v.Board.validCountry(c); err != nil {
+			return fmt.Errorf("variant %s: %w", v.Name, err)
+		}
+	}
+	if v.Setup == nil {
+		if _, err := NewStartingGame(v.Board); err != nil {
+			return fmt.Errorf("variant %s: %w", v.Name, err)
+		}
+	}
+	v.Countries = maps.Clone(v.Countries)
+	variantsMu.Lock()
+	defer variantsMu.Unlock()
+	key := strings.ToLower(v.Name)
+	if _, ok := variants[key]; ok {
+		return fmt.Errorf("variant %s already registered", v.Name)
+	}
+	if _, ok := boardName(v.Board); !ok {
+		if err := RegisterBoard(v.Name, v.Board); err != nil {
+			return err
+		}
+	}
+	variants[key] = v
+	return nil
+}
+
+// RegisteredVariant gets a variant by the name it was registered with.
+func RegisteredVariant(name string) (Variant, bool) {
+	variantsMu.RLock()
+	defer variantsMu.RUnlock()
+	v, ok := variants[strings.ToLower(name)]
+	return v, ok
+}
+
+// Variants is all registered variants, in order of name.
+func Variants() []Variant {
+	variantsMu.RLock()
+	defer variantsMu.RUnlock()
+	return slices.SortedFunc(maps.Values(variants), func(a, b Variant) int {
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+}
+
+// FleetRomeBoard is the board for Fleet Rome, the standard game in which
+// Italy starts with a Fleet in Rome instead of an Army.
+var FleetRomeBoard *Board
+
+func init() {
+	b := standardBuilder()
+	for i, u := range b.Units {
+		if u.Province == "Rome" {
+			b.Units[i].Unit = "Fleet"
+		}
+	}
+	FleetRomeBoard = mustBuildBundled(b)
+	mustRegisterVariant(Variant{
+		Name:        "Fleet Rome",
+		Description: "The standard game, with Italy starting with a Fleet in Rome instead of an Army.",
+		Board:       FleetRomeBoard,
+		Countries:   standardCountries,
+	})
+}
+
+// TODO bundle 1900, Ancient Mediterranean (with ConvoyViaCoasts for its
+// canals), and Youngstown Redux. Their boards are still to be transcribed
+// from the published maps; the bundled board tests cover them once they
+// are registered.
+
+// mustBuildBundled builds a board shipped with the package. It panics on
+// any error.
+func mustBuildBundled(b Builder) *Board {
+	board, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return board
+}
+
+// mustRegisterVariant registers a variant shipped with the package.
+func mustRegisterVariant(v Variant) {
+	if err := RegisterVariant(v); err != nil {
+		panic(err)
+	}
+}
+
+// VariantOf gets the first registered variant, by name, played on a board.
+// If there is none, a variant with only the board is given, and ok is false.
+func VariantOf(board *Board) (v Variant, ok bool) {
+	for _, v := range Variants() {
+		if v.Board == board {
+			return v, true
+		}
+	}
+	return Variant{Board: board}, false
+}
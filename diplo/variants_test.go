@@ -0,0 +1,86 @@
+package diplo
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBundledConnectionsSymmetric(t *testing.T) {
+	for _, v := range Variants() {
+		for c := range v.Board.Connections() {
+			r := v.Board.Connection(c.to, c.from)
+			if r == nil || r.from != c.to || r.to != c.from ||
+				!slices.Equal(r.fromCoasts, c.toCoasts) || !slices.Equal(r.toCoasts, c.fromCoasts) {
+				t.Errorf("%s: connection %s %v - %s %v is not symmetric",
+					v.Name, c.from.name, c.fromCoasts, c.to.name, c.toCoasts)
+			}
+		}
+	}
+}
+
+func TestBundledStartingUnits(t *testing.T) {
+	for _, v := range Variants() {
+		g, err := v.NewGame()
+		if err != nil {
+			t.Errorf("%s: %v", v.Name, err)
+			continue
+		}
+		for _, c := range v.Board.Countries() {
+			homes := 0
+			for p := range v.Board.HomeCenters(c) {
+				homes++
+				if u := g.Unit(p); u == nil || u.country != c {
+					t.Errorf("%s: %s home center %s has no %s unit", v.Name, c, p.name, c)
+				}
+			}
+			if n := g.UnitCount(c); n != homes {
+				t.Errorf("%s: %s has %d units but %d home centers", v.Name, c, n, homes)
+			}
+		}
+	}
+}
+
+func TestFleetRome(t *testing.T) {
+	v, ok := RegisteredVariant("fleet rome")
+	if !ok {
+		t.Fatal("Fleet Rome is not registered")
+	}
+	g, err := v.NewGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := g.Unit(v.Board.ParseProvince("Rome")[0]); u == nil || u.unit != Fleet || u.country != "Italy" {
+		t.Errorf("Rome has %v, want an Italian Fleet", u)
+	}
+	if got, want := g.Notation(), StandardGame().Notation(); got == want {
+		t.Errorf("Fleet Rome starts as the standard game: %s", got)
+	}
+}
+
+func TestRegisterVariantChecksSetup(t *testing.T) {
+	b := standardBuilder()
+	b.Units = slices.DeleteFunc(b.Units, func(u BuilderUnit) bool {
+		return u.Province == "Rome"
+	})
+	board, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterVariant(Variant{Name: "No Rome", Board: board}); err == nil {
+		t.Error("registered a variant with an empty home center")
+	}
+}
+
+func TestVariantOf(t *testing.T) {
+	if v, ok := VariantOf(FleetRomeBoard); !ok || v.Name != "Fleet Rome" {
+		t.Errorf("VariantOf(FleetRomeBoard) = %q, %v", v.Name, ok)
+	}
+	b := standardBuilder()
+	board, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := VariantOf(board); ok || v.Board != board {
+		t.Errorf("VariantOf(unregistered board) = %q, %v", v.Name, ok)
+	}
+}